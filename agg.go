@@ -0,0 +1,43 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"fmt"
+)
+
+// Magic aliases which can be used in ORDER BY and WITHIN GROUP ORDER BY of a grouped query.
+const (
+	MagicGroupBy = "@groupby"
+	MagicCount   = "@count"
+)
+
+// Agg provides several helper methods to build grouping functions.
+type Agg struct{}
+
+// GroupByKey represents "GROUPBY()", which returns the key of the current group.
+func (a *Agg) GroupByKey() string {
+	return "GROUPBY()"
+}
+
+// GroupConcat represents "GROUP_CONCAT(expr)".
+func (a *Agg) GroupConcat(expr string) string {
+	return fmt.Sprintf("GROUP_CONCAT(%s)", expr)
+}
+
+// Count represents "COUNT(expr)".
+// If expr is empty, it represents "COUNT(*)".
+func (a *Agg) Count(expr string) string {
+	if expr == "" {
+		expr = "*"
+	}
+
+	return fmt.Sprintf("COUNT(%s)", expr)
+}
+
+// CountDistinct represents "COUNT(DISTINCT field)".
+func (a *Agg) CountDistinct(field string) string {
+	return fmt.Sprintf("COUNT(DISTINCT %s)", Escape(field))
+}
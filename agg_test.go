@@ -0,0 +1,31 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestAgg(t *testing.T) {
+	a := assert.New(t)
+	cases := map[string]func() string{
+		"GROUPBY()":              func() string { return newTestAgg().GroupByKey() },
+		"GROUP_CONCAT(id)":       func() string { return newTestAgg().GroupConcat("id") },
+		"COUNT(*)":               func() string { return newTestAgg().Count("") },
+		"COUNT(id)":              func() string { return newTestAgg().Count("id") },
+		"COUNT(DISTINCT $$city)": func() string { return newTestAgg().CountDistinct("$city") },
+	}
+
+	for expected, f := range cases {
+		actual := f()
+		a.Equal(actual, expected)
+	}
+}
+
+func newTestAgg() *Agg {
+	return &Agg{}
+}
@@ -215,13 +215,17 @@ func (args *Args) compileArg(buf *strings.Builder, flavor Flavor, values []inter
 	switch a := arg.(type) {
 	case Builder:
 		var s string
-		s, values = a.BuildWithFlavor(flavor, values...)
+		s, values = buildNested(a, flavor, values...)
 		buf.WriteString(s)
 	case sql.NamedArg:
 		buf.WriteRune('@')
 		buf.WriteString(a.Name)
 	case rawArgs:
 		buf.WriteString(a.expr)
+	case emptyListArgs:
+		a.writeTo(buf, flavor)
+	case inSliceArgs:
+		values = a.writeTo(args, buf, flavor, values)
 	case listArgs:
 		if len(a.args) > 0 {
 			values = args.compileArg(buf, flavor, values, a.args[0])
@@ -239,3 +243,61 @@ func (args *Args) compileArg(buf *strings.Builder, flavor Flavor, values []inter
 
 	return values
 }
+
+// clone returns a deep copy of args.
+// Nested builders created by this package are cloned as well.
+// cloneArg clones builders in arg, including builders in lists, so that the clone doesn't share them.
+func cloneArg(arg interface{}) interface{} {
+	switch a := arg.(type) {
+	case clonableBuilder:
+		return a.cloneBuilder()
+	case listArgs:
+		return listArgs{args: cloneArgs(a.args)}
+	case inSliceArgs:
+		a.values = cloneArgs(a.values)
+		return a
+	}
+
+	return arg
+}
+
+func cloneArgs(args []interface{}) []interface{} {
+	if args == nil {
+		return nil
+	}
+
+	c := make([]interface{}, 0, len(args))
+
+	for _, arg := range args {
+		c = append(c, cloneArg(arg))
+	}
+
+	return c
+}
+
+func (args *Args) clone() *Args {
+	c := &Args{
+		Flavor:    args.Flavor,
+		onlyNamed: args.onlyNamed,
+	}
+
+	c.args = cloneArgs(args.args)
+
+	if args.namedArgs != nil {
+		c.namedArgs = make(map[string]int, len(args.namedArgs))
+
+		for k, v := range args.namedArgs {
+			c.namedArgs[k] = v
+		}
+	}
+
+	if args.sqlNamedArgs != nil {
+		c.sqlNamedArgs = make(map[string]int, len(args.sqlNamedArgs))
+
+		for k, v := range args.sqlNamedArgs {
+			c.sqlNamedArgs[k] = v
+		}
+	}
+
+	return c
+}
@@ -16,6 +16,11 @@ type Builder interface {
 	BuildWithFlavor(flavor Flavor, initialArg ...interface{}) (sql string, args []interface{})
 }
 
+// clonableBuilder is a builder which can be deeply copied by `Args#clone`.
+type clonableBuilder interface {
+	cloneBuilder() Builder
+}
+
 type compiledBuilder struct {
 	args   *Args
 	format string
@@ -24,13 +29,22 @@ type compiledBuilder struct {
 var _ Builder = new(compiledBuilder)
 
 func (cb *compiledBuilder) Build() (sql string, args []interface{}) {
-	return cb.args.Compile(cb.format)
+	return cb.BuildWithFlavor(cb.args.Flavor)
 }
 
 func (cb *compiledBuilder) BuildWithFlavor(flavor Flavor, initialArg ...interface{}) (sql string, args []interface{}) {
+	sql, args = cb.build(flavor, initialArg...)
+	return runHooks(cb, flavor, sql, args)
+}
+
+func (cb *compiledBuilder) build(flavor Flavor, initialArg ...interface{}) (sql string, args []interface{}) {
 	return cb.args.CompileWithFlavor(cb.format, flavor, initialArg...)
 }
 
+func (cb *compiledBuilder) defaultFlavor() Flavor {
+	return cb.args.Flavor
+}
+
 type flavoredBuilder struct {
 	builder Builder
 	flavor  Flavor
@@ -44,6 +58,14 @@ func (fb *flavoredBuilder) BuildWithFlavor(flavor Flavor, initialArg ...interfac
 	return fb.builder.BuildWithFlavor(flavor, initialArg...)
 }
 
+func (fb *flavoredBuilder) build(flavor Flavor, initialArg ...interface{}) (sql string, args []interface{}) {
+	return buildNested(fb.builder, flavor, initialArg...)
+}
+
+func (fb *flavoredBuilder) defaultFlavor() Flavor {
+	return fb.flavor
+}
+
 // WithFlavor creates a new Builder based on builder with a default flavor.
 func WithFlavor(builder Builder, flavor Flavor) Builder {
 	return &flavoredBuilder{
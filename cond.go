@@ -75,7 +75,12 @@ func (c *Cond) LE(field string, value interface{}) string {
 }
 
 // In represents "field IN (value...)".
+// If there is no value, the expression is rendered according to `EmptyListPolicy` of the flavor.
 func (c *Cond) In(field string, value ...interface{}) string {
+	if isEmptyList(value) {
+		return c.Args.Add(emptyListArgs{field: field, not: false})
+	}
+
 	vs := make([]string, 0, len(value))
 
 	for _, v := range value {
@@ -86,7 +91,12 @@ func (c *Cond) In(field string, value ...interface{}) string {
 }
 
 // NotIn represents "field NOT IN (value...)".
+// If there is no value, the expression is rendered according to `EmptyListPolicy` of the flavor.
 func (c *Cond) NotIn(field string, value ...interface{}) string {
+	if isEmptyList(value) {
+		return c.Args.Add(emptyListArgs{field: field, not: true})
+	}
+
 	vs := make([]string, 0, len(value))
 
 	for _, v := range value {
@@ -136,11 +146,29 @@ func (c *Cond) And(andExpr ...string) string {
 	return fmt.Sprintf("(%s)", strings.Join(andExpr, " AND "))
 }
 
+// Not represents the negation of expr.
+// As SphinxQL doesn't support NOT in most places, the operator is pushed down to the leaves:
+// "=" becomes "<>", "<" becomes ">=", IN becomes NOT IN, BETWEEN becomes NOT BETWEEN,
+// and AND/OR are swapped by De Morgan's laws.
+// Expressions which cannot be rewritten, e.g. a `RawExpr`, are wrapped in "NOT (expr)".
+//
+// MATCH cannot be negated in SphinxQL. `SelectBuilder#Validate` reports it with ErrMatchInsideNot.
+func (c *Cond) Not(expr Expr) string {
+	return c.Expr(Not(expr))
+}
+
 // Match represents "MATCH('value')".
 func (c *Cond) Match(value string) string {
 	return fmt.Sprintf("MATCH(%s)", c.Args.Add(value))
 }
 
+// MatchText represents "MATCH('text')", where text is matched literally.
+// Full-text operators in text are escaped by `EscapeMatch` during interpolation,
+// so text can come from user input safely.
+func (c *Cond) MatchText(text string) string {
+	return fmt.Sprintf("MATCH(%s)", c.Args.Add(matchTextArgs{text}))
+}
+
 // Var returns a placeholder for value.
 func (c *Cond) Var(value interface{}) string {
 	return c.Args.Add(value)
@@ -36,6 +36,7 @@ func TestCond(t *testing.T) {
 		"(1 = 1 OR 2 = 2 OR 3 = 3)":   func() string { return newTestCond().Or("1 = 1", "2 = 2", "3 = 3") },
 		"(1 = 1 AND 2 = 2 AND 3 = 3)": func() string { return newTestCond().And("1 = 1", "2 = 2", "3 = 3") },
 		"$0":                          func() string { return newTestCond().Var(123) },
+		"MATCH($0)":                   func() string { return newTestCond().MatchText("a-b") },
 	}
 
 	for expected, f := range cases {
@@ -49,3 +50,32 @@ func newTestCond() *Cond {
 		Args: &Args{},
 	}
 }
+
+func TestCondNot(t *testing.T) {
+	a := assert.New(t)
+	cases := map[string]Expr{
+		"a <> $0":                              Compare("a", OpEqual, 1),
+		"a = $0":                               Compare("a", OpNotEqual, 1),
+		"a <= $0":                              Compare("a", OpGreater, 1),
+		"a < $0":                               Compare("a", OpGreaterEqual, 1),
+		"a >= $0":                              Compare("a", OpLess, 1),
+		"a > $0":                               Compare("a", OpLessEqual, 1),
+		"a NOT LIKE $0":                        Compare("a", OpLike, "x%"),
+		"a LIKE $0":                            Compare("a", OpNotLike, "x%"),
+		"a NOT IN ($0, $1)":                    In("a", 1, 2),
+		"a IN ($0, $1)":                        NotIn("a", 1, 2),
+		"a NOT BETWEEN $0 AND $1":              Between("a", 1, 2),
+		"a BETWEEN $0 AND $1":                  BetweenExpr{Field: "a", Lower: 1, Upper: 2, Not: true},
+		"b <> $0":                              Not(Not(Compare("b", OpEqual, 1))),
+		"(a <> $0 OR b NOT IN ($1))":           And(Compare("a", OpEqual, 1), In("b", 2)),
+		"(a <> $0 AND (b IN ($1) OR c <= $2))": Or(Compare("a", OpEqual, 1), And(NotIn("b", 2), Compare("c", OpGreater, 3))),
+		"NOT (a > 1)":                          RawExpr("a > 1"),
+		"NOT (MATCH($0))":                      Match("x"),
+		"(NOT (a > 1) OR b >= $0)":             And(RawExpr("a > 1"), Compare("b", OpLess, 1)),
+	}
+
+	for expected, expr := range cases {
+		a.Use(&expected, &expr)
+		a.Equal(newTestCond().Not(expr), expected)
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// ErrInterpolateNamedArgs means that a named arg like `sql.Named` is passed to a connection
+// created by `NewConnector`, which supports positional args only.
+var ErrInterpolateNamedArgs = errors.New("go-sphinxql: named args cannot be interpolated")
+
+// NewConnector wraps connector, so that args of queries are interpolated by flavor client-side
+// and the queries are sent to the server as plain text.
+//
+// The searchd doesn't support server-side prepared statements, so it's the safe way
+// to use `DB#Query` and `DB#Exec` with args against it.
+//
+//	db := sql.OpenDB(sphinxql.NewConnector(mysqlConnector, sphinxql.SphinxSearch))
+//	query, args := sb.Build()
+//	rows, err := db.Query(query, args...)
+//
+// Statements created by `DB#Prepare` are interpolated client-side too.
+// Args are passed to `Flavor#Interpolate` as is, so all types supported by interpolation,
+// e.g. `FloatVector`, can be used.
+func NewConnector(connector driver.Connector, flavor Flavor) driver.Connector {
+	return &interpolatingConnector{
+		connector: connector,
+		flavor:    flavor,
+	}
+}
+
+type interpolatingConnector struct {
+	connector driver.Connector
+	flavor    Flavor
+}
+
+var _ driver.Connector = new(interpolatingConnector)
+
+func (c *interpolatingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &interpolatingConn{conn: conn, flavor: c.flavor}, nil
+}
+
+func (c *interpolatingConnector) Driver() driver.Driver {
+	return &interpolatingDriver{driver: c.connector.Driver(), flavor: c.flavor}
+}
+
+type interpolatingDriver struct {
+	driver driver.Driver
+	flavor Flavor
+}
+
+func (d *interpolatingDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.driver.Open(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &interpolatingConn{conn: conn, flavor: d.flavor}, nil
+}
+
+type interpolatingConn struct {
+	conn   driver.Conn
+	flavor Flavor
+}
+
+var (
+	_ driver.Conn               = new(interpolatingConn)
+	_ driver.ConnBeginTx        = new(interpolatingConn)
+	_ driver.ConnPrepareContext = new(interpolatingConn)
+	_ driver.QueryerContext     = new(interpolatingConn)
+	_ driver.ExecerContext      = new(interpolatingConn)
+	_ driver.NamedValueChecker  = new(interpolatingConn)
+	_ driver.Pinger             = new(interpolatingConn)
+	_ driver.SessionResetter    = new(interpolatingConn)
+	_ driver.Validator          = new(interpolatingConn)
+	_ driver.StmtQueryContext   = new(interpolatingStmt)
+	_ driver.StmtExecContext    = new(interpolatingStmt)
+	_ driver.NamedValueChecker  = new(interpolatingStmt)
+	_ driver.Rows               = new(stmtRows)
+)
+
+// Prepare returns a statement which is interpolated client-side on every execution.
+func (c *interpolatingConn) Prepare(query string) (driver.Stmt, error) {
+	return &interpolatingStmt{conn: c, query: query}, nil
+}
+
+func (c *interpolatingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Prepare(query)
+}
+
+func (c *interpolatingConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *interpolatingConn) Begin() (driver.Tx, error) {
+	return c.conn.Begin()
+}
+
+func (c *interpolatingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bt, ok := c.conn.(driver.ConnBeginTx); ok {
+		return bt.BeginTx(ctx, opts)
+	}
+
+	return c.conn.Begin()
+}
+
+// CheckNamedValue accepts all values, as they are interpolated by the flavor instead of the driver.
+func (c *interpolatingConn) CheckNamedValue(nv *driver.NamedValue) error {
+	return nil
+}
+
+func (c *interpolatingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	query, err := c.interpolate(query, args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch conn := c.conn.(type) {
+	case driver.QueryerContext:
+		return conn.QueryContext(ctx, query, nil)
+	case driver.Queryer:
+		return conn.Query(query, nil)
+	}
+
+	stmt, err := c.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.Query(nil)
+
+	if err != nil {
+		stmt.Close()
+		return nil, err
+	}
+
+	return &stmtRows{Rows: rows, stmt: stmt}, nil
+}
+
+func (c *interpolatingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	query, err := c.interpolate(query, args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch conn := c.conn.(type) {
+	case driver.ExecerContext:
+		return conn.ExecContext(ctx, query, nil)
+	case driver.Execer:
+		return conn.Exec(query, nil)
+	}
+
+	stmt, err := c.prepare(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer stmt.Close()
+	return stmt.Exec(nil)
+}
+
+func (c *interpolatingConn) Ping(ctx context.Context) error {
+	if p, ok := c.conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *interpolatingConn) ResetSession(ctx context.Context) error {
+	if sr, ok := c.conn.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *interpolatingConn) IsValid() bool {
+	if v, ok := c.conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+
+	return true
+}
+
+// prepare prepares an interpolated query with the underlying driver.
+// It's used only if the driver cannot run a query without preparing it.
+func (c *interpolatingConn) prepare(ctx context.Context, query string) (driver.Stmt, error) {
+	if pc, ok := c.conn.(driver.ConnPrepareContext); ok {
+		return pc.PrepareContext(ctx, query)
+	}
+
+	return c.conn.Prepare(query)
+}
+
+func (c *interpolatingConn) interpolate(query string, args []driver.NamedValue) (string, error) {
+	if len(args) == 0 {
+		return query, nil
+	}
+
+	values := make([]interface{}, 0, len(args))
+
+	for _, arg := range args {
+		if arg.Name != "" {
+			return "", fmt.Errorf("%w: %s", ErrInterpolateNamedArgs, arg.Name)
+		}
+
+		values = append(values, arg.Value)
+	}
+
+	return c.flavor.Interpolate(query, values)
+}
+
+// interpolatingStmt is a statement prepared client-side.
+type interpolatingStmt struct {
+	conn  *interpolatingConn
+	query string
+}
+
+func (s *interpolatingStmt) Close() error {
+	return nil
+}
+
+// NumInput returns -1, as the number of placeholders is checked by interpolation.
+func (s *interpolatingStmt) NumInput() int {
+	return -1
+}
+
+func (s *interpolatingStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	return nil
+}
+
+func (s *interpolatingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *interpolatingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *interpolatingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *interpolatingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	nvs := make([]driver.NamedValue, 0, len(args))
+
+	for i, arg := range args {
+		nvs = append(nvs, driver.NamedValue{Ordinal: i + 1, Value: arg})
+	}
+
+	return nvs
+}
+
+// stmtRows closes the statement of rows with rows.
+type stmtRows struct {
+	driver.Rows
+	stmt driver.Stmt
+}
+
+func (r *stmtRows) Close() error {
+	err := r.Rows.Close()
+
+	if e := r.stmt.Close(); err == nil {
+		err = e
+	}
+
+	return err
+}
@@ -0,0 +1,171 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+// fakeConnector records queries sent by database/sql.
+// Its connections don't support prepared statements at all like searchd.
+type fakeConnector struct {
+	queries []string
+}
+
+type fakeConn struct {
+	connector *fakeConnector
+}
+
+type fakeRows struct {
+	done bool
+}
+
+var errFakePrepare = errors.New("prepared statements are not supported")
+
+func (c *fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{connector: c}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errFakePrepare
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) > 0 {
+		return nil, errFakePrepare
+	}
+
+	c.connector.queries = append(c.connector.queries, query)
+	return &fakeRows{}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) > 0 {
+		return nil, errFakePrepare
+	}
+
+	c.connector.queries = append(c.connector.queries, query)
+	return driver.RowsAffected(1), nil
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+func TestConnector(t *testing.T) {
+	a := assert.New(t)
+	fake := &fakeConnector{}
+	db := sql.OpenDB(NewConnector(fake, SphinxSearch))
+	defer db.Close()
+
+	sb := NewSelectBuilder()
+	sb.Select("id").From("idx")
+	sb.Where(sb.Match("go"), sb.Equal("token", Sensitive("x'y")))
+	sb.OrderBy(sb.KNNDist())
+	sb.KNN("vec", 5, []float32{0.5, 1}, 0)
+
+	var id int
+	query, args := sb.Build()
+	a.NilError(db.QueryRow(query, args...).Scan(&id))
+	a.Equal(id, 1)
+
+	ib := NewInsertBuilder()
+	ib.InsertInto("idx").Cols("id", "title").Values(1, "it's")
+	query, args = ib.Build()
+	res, err := db.Exec(query, args...)
+	a.NilError(err)
+	n, _ := res.RowsAffected()
+	a.Equal(n, int64(1))
+
+	stmt, err := db.Prepare("DELETE FROM idx WHERE id = ?")
+	a.NilError(err)
+	_, err = stmt.Exec(2)
+	a.NilError(err)
+	_, err = stmt.Exec(3)
+	a.NilError(err)
+	a.NilError(stmt.Close())
+
+	_, err = db.Exec("SELECT 1")
+	a.NilError(err)
+
+	a.Equal(fake.queries, []string{
+		"SELECT id FROM idx WHERE MATCH('go') AND token = 'x\\'y' AND KNN(vec, 5, (0.5, 1)) ORDER BY KNN_DIST()",
+		"INSERT INTO idx (id, title) VALUES (1, 'it\\'s')",
+		"DELETE FROM idx WHERE id = 2",
+		"DELETE FROM idx WHERE id = 3",
+		"SELECT 1",
+	})
+
+	_, err = db.Exec("SELECT ?", sql.Named("a", 1))
+	a.Assert(errors.Is(err, ErrInterpolateNamedArgs))
+
+	_, err = db.Exec("SELECT ?, ?", 1)
+	a.Equal(err, ErrInterpolateMissingArgs)
+}
+
+func TestBuildInterpolated(t *testing.T) {
+	a := assert.New(t)
+
+	ib := SphinxSearch.NewInsertBuilder()
+	ib.InsertInto("idx").Cols("id", "title").Values(1, "go")
+	s, err := ib.BuildInterpolated()
+	a.NilError(err)
+	a.Equal(s, "INSERT INTO idx (id, title) VALUES (1, 'go')")
+	a.Equal(ib.StringInterpolated(), s)
+
+	ub := SphinxSearch.NewUpdateBuilder()
+	ub.Update("idx").Set(ub.Assign("price", 10)).Where(ub.Equal("id", 1))
+	s, err = ub.BuildInterpolated()
+	a.NilError(err)
+	a.Equal(s, "UPDATE idx SET price = 10 WHERE id = 1")
+	a.Equal(ub.StringInterpolated(), s)
+
+	db := SphinxSearch.NewDeleteBuilder()
+	db.DeleteFrom("idx").Where(db.In("id", 1, 2))
+	s, err = db.BuildInterpolated()
+	a.NilError(err)
+	a.Equal(s, "DELETE FROM idx WHERE id IN (1, 2)")
+	a.Equal(db.StringInterpolated(), s)
+
+	// StringInterpolated marks the error of interpolation.
+	sb := SphinxSearch.NewSelectBuilder()
+	sb.Select("id").From("idx").Where(sb.Equal("a", struct{}{}))
+	_, err = sb.BuildInterpolated()
+	a.Equal(err, ErrInterpolateUnsupportedArgs)
+	a.Equal(sb.StringInterpolated(), "[ERROR: go-sphinxql: unsupported args when interpolating] SELECT id FROM idx WHERE a = ?")
+}
@@ -66,10 +66,25 @@ func (db *DeleteBuilder) Where(andExpr ...string) *DeleteBuilder {
 
 // String returns the compiled DELETE string.
 func (db *DeleteBuilder) String() string {
-	s, _ := db.Build()
+	s, _ := db.build(db.args.Flavor)
 	return s
 }
 
+// StringInterpolated returns the compiled DELETE string with args interpolated.
+// Like String, it doesn't call hooks. It's designed for logging and debugging;
+// use BuildInterpolated to send a query, as the error of interpolation is returned in place of the query.
+func (db *DeleteBuilder) StringInterpolated() string {
+	sql, args := db.build(db.args.Flavor)
+	return interpolatedString(db.args.Flavor, sql, args)
+}
+
+// BuildInterpolated returns compiled DELETE string with args interpolated by the flavor of db.
+// It's designed for searchd, which doesn't support server-side prepared statements.
+func (db *DeleteBuilder) BuildInterpolated() (string, error) {
+	sql, args := db.Build()
+	return db.args.Flavor.Interpolate(sql, args)
+}
+
 // Build returns compiled DELETE string and args.
 // They can be used in `DB#Query` of package `database/sql` directly.
 func (db *DeleteBuilder) Build() (sql string, args []interface{}) {
@@ -79,6 +94,12 @@ func (db *DeleteBuilder) Build() (sql string, args []interface{}) {
 // BuildWithFlavor returns compiled DELETE string and args with flavor and initial args.
 // They can be used in `DB#Query` of package `database/sql` directly.
 func (db *DeleteBuilder) BuildWithFlavor(flavor Flavor, initialArg ...interface{}) (sql string, args []interface{}) {
+	sql, args = db.build(flavor, initialArg...)
+	return runHooks(db, flavor, sql, args)
+}
+
+// build builds db without calling hooks.
+func (db *DeleteBuilder) build(flavor Flavor, initialArg ...interface{}) (sql string, args []interface{}) {
 	buf := &strings.Builder{}
 	db.injection.WriteTo(buf, deleteMarkerInit)
 	buf.WriteString("DELETE FROM ")
@@ -95,6 +116,22 @@ func (db *DeleteBuilder) BuildWithFlavor(flavor Flavor, initialArg ...interface{
 	return db.args.CompileWithFlavor(buf.String(), flavor, initialArg...)
 }
 
+// Validate checks db for mistakes which are not reported until the query reaches the server.
+// It returns the first found error or nil.
+func (db *DeleteBuilder) Validate() error {
+	return db.ValidateWithFlavor(db.args.Flavor)
+}
+
+// ValidateWithFlavor checks db like Validate with settings of flavor,
+// which must be the flavor passed to `BuildWithFlavor`.
+func (db *DeleteBuilder) ValidateWithFlavor(flavor Flavor) error {
+	return validateEmptyList(db.args, flavor)
+}
+
+func (db *DeleteBuilder) defaultFlavor() Flavor {
+	return db.args.Flavor
+}
+
 // SetFlavor sets the flavor of compiled sql.
 func (db *DeleteBuilder) SetFlavor(flavor Flavor) (old Flavor) {
 	old = db.args.Flavor
@@ -102,6 +139,27 @@ func (db *DeleteBuilder) SetFlavor(flavor Flavor) (old Flavor) {
 	return
 }
 
+// Clone returns a deep copy of db.
+// Args, injected SQLs and nested builders are copied, so db and the copy
+// can be modified and built independently, e.g. in different goroutines.
+func (db *DeleteBuilder) Clone() *DeleteBuilder {
+	args := db.args.clone()
+	return &DeleteBuilder{
+		Cond: Cond{
+			Args: args,
+		},
+		table:      db.table,
+		whereExprs: cloneStrings(db.whereExprs),
+		args:       args,
+		injection:  db.injection.clone(),
+		marker:     db.marker,
+	}
+}
+
+func (db *DeleteBuilder) cloneBuilder() Builder {
+	return db.Clone()
+}
+
 // SQL adds an arbitrary sql to current position.
 func (db *DeleteBuilder) SQL(sql string) *DeleteBuilder {
 	db.injection.SQL(db.marker, sql)
@@ -6,6 +6,9 @@ package sphinxql
 
 import (
 	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
 )
 
 func ExampleDeleteFrom() {
@@ -61,3 +64,20 @@ func ExampleDeleteBuilder_SQL() {
 	// /* before */ DELETE FROM demo.user PARTITION (p0) WHERE id > ? /* after where */
 	// [1234]
 }
+
+func TestDeleteBuilderClone(t *testing.T) {
+	a := assert.New(t)
+	base := NewDeleteBuilder()
+	base.DeleteFrom("vacancies").Where(base.E("status", 0))
+
+	c := base.Clone()
+	c.Where(c.In("id", 1, 2))
+
+	s, args := base.Build()
+	a.Equal(s, "DELETE FROM vacancies WHERE status = ?")
+	a.Equal(args, []interface{}{0})
+
+	s, args = c.Build()
+	a.Equal(s, "DELETE FROM vacancies WHERE status = ? AND id IN (?, ?)")
+	a.Equal(args, []interface{}{0, 1, 2})
+}
@@ -0,0 +1,127 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrEmptyList means that IN, NOT IN or `List` gets no values while the EmptyListError policy is set.
+	ErrEmptyList = errors.New("go-sphinxql: empty list of values")
+)
+
+// EmptyListPolicy controls how `Cond#In` and `Cond#NotIn` are rendered without values.
+// The searchd refuses "field IN ()" as a syntax error.
+type EmptyListPolicy int
+
+// EmptyListPolicy enum.
+const (
+	// EmptyListMatchNone renders an empty IN as "1=0" and an empty NOT IN as "1=1",
+	// which is the logical result of the operators. It's the default policy.
+	EmptyListMatchNone EmptyListPolicy = iota
+
+	// EmptyListMatchAll renders both an empty IN and an empty NOT IN as "1=1",
+	// so an empty filter doesn't filter anything.
+	EmptyListMatchAll
+
+	// EmptyListKeep renders "field IN ()" as is.
+	EmptyListKeep
+
+	// EmptyListError renders "field IN ()" as is and makes `Validate` of builders return ErrEmptyList.
+	EmptyListError
+)
+
+// SetEmptyListPolicy sets the policy for empty IN and NOT IN for all builders with flavor f.
+// The policy is applied when a builder is built, so it affects nested builders as well.
+//
+// A `List` with no values used in a format string, e.g. `Buildf("id IN (%v)", List(ids))`,
+// cannot be rewritten and is always rendered as is. Only EmptyListError reports it.
+//
+// SetEmptyListPolicy is expected to be called once during initialization.
+func (f Flavor) SetEmptyListPolicy(policy EmptyListPolicy) {
+	f.updateSettings(func(fs *flavorSettings) {
+		fs.emptyListPolicy = policy
+	})
+}
+
+// EmptyListPolicy returns the policy for empty IN and NOT IN of flavor f.
+func (f Flavor) EmptyListPolicy() EmptyListPolicy {
+	return f.settings().emptyListPolicy
+}
+
+// emptyListArgs is a placeholder of "field IN ()" or "field NOT IN ()".
+// It's rendered by the policy of the flavor when the builder is compiled.
+type emptyListArgs struct {
+	field string
+	not   bool
+}
+
+func (e emptyListArgs) writeTo(buf *strings.Builder, flavor Flavor) {
+	switch flavor.EmptyListPolicy() {
+	case EmptyListMatchNone:
+		if e.not {
+			buf.WriteString("1=1")
+		} else {
+			buf.WriteString("1=0")
+		}
+
+		return
+	case EmptyListMatchAll:
+		buf.WriteString("1=1")
+		return
+	}
+
+	// The field is written to the compiled SQL, so it must not be escaped.
+	buf.WriteString(e.field)
+
+	if e.not {
+		buf.WriteString(" NOT IN ()")
+	} else {
+		buf.WriteString(" IN ()")
+	}
+}
+
+// isEmptyList returns true if values contain nothing but empty `List`s.
+func isEmptyList(values []interface{}) bool {
+	for _, v := range values {
+		if l, ok := v.(listArgs); !ok || len(l.args) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateEmptyList returns ErrEmptyList if args contain an empty list and
+// the policy of flavor is EmptyListError.
+// Nested builders are validated with the same flavor, as they are built with it.
+func validateEmptyList(args *Args, flavor Flavor) error {
+	policy := flavor.EmptyListPolicy()
+
+	for _, arg := range args.args {
+		switch a := arg.(type) {
+		case emptyListArgs:
+			if policy == EmptyListError {
+				return ErrEmptyList
+			}
+		case listArgs:
+			if len(a.args) == 0 && policy == EmptyListError {
+				return ErrEmptyList
+			}
+		case interface{ ValidateWithFlavor(flavor Flavor) error }:
+			if err := a.ValidateWithFlavor(flavor); err != nil {
+				return err
+			}
+		case interface{ Validate() error }:
+			if err := a.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func ExampleFlavor_SetEmptyListPolicy() {
+	var cityIDs []int
+
+	sb := NewSelectBuilder()
+	sb.Select("id").From("vacancies")
+	sb.Where(sb.In("city_id", List(cityIDs)), sb.NotIn("company_id"))
+
+	s, _ := sb.Build()
+	fmt.Println(s)
+
+	// Output:
+	// SELECT id FROM vacancies WHERE 1=0 AND 1=1
+}
+
+func setTestEmptyListPolicy(t *testing.T, policy EmptyListPolicy) {
+	old := DefaultFlavor.EmptyListPolicy()
+	DefaultFlavor.SetEmptyListPolicy(policy)
+	t.Cleanup(func() {
+		DefaultFlavor.SetEmptyListPolicy(old)
+	})
+}
+
+func TestEmptyListPolicy(t *testing.T) {
+	cases := []struct {
+		policy   EmptyListPolicy
+		expected string
+	}{
+		{EmptyListMatchNone, "SELECT id FROM vacancies WHERE 1=0 AND 1=1 AND status IN (?) AND 1=0 AND id IN (SELECT id FROM companies WHERE 1=0)"},
+		{EmptyListMatchAll, "SELECT id FROM vacancies WHERE 1=1 AND 1=1 AND status IN (?) AND 1=1 AND id IN (SELECT id FROM companies WHERE 1=1)"},
+		{EmptyListKeep, "SELECT id FROM vacancies WHERE city_id IN () AND company_id NOT IN () AND status IN (?) AND tag_id IN () AND id IN (SELECT id FROM companies WHERE region_id IN ())"},
+		{EmptyListError, "SELECT id FROM vacancies WHERE city_id IN () AND company_id NOT IN () AND status IN (?) AND tag_id IN () AND id IN (SELECT id FROM companies WHERE region_id IN ())"},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprint(c.policy), func(t *testing.T) {
+			a := assert.New(t)
+			setTestEmptyListPolicy(t, c.policy)
+
+			nested := NewSelectBuilder()
+			nested.Select("id").From("companies").Where(nested.In("region_id", List([]int{})))
+
+			sb := NewSelectBuilder()
+			sb.Select("id").From("vacancies")
+			sb.Where(
+				sb.In("city_id"),
+				sb.NotIn("company_id", List([]string(nil))),
+				sb.In("status", List([]int{1})),
+			)
+			sb.WhereExpr(In("tag_id"))
+			sb.Where(sb.In("id", nested))
+
+			s, args := sb.Build()
+			a.Equal(s, c.expected)
+			a.Equal(args, []interface{}{1})
+		})
+	}
+}
+
+func TestEmptyListValidate(t *testing.T) {
+	a := assert.New(t)
+
+	sb := NewSelectBuilder()
+	sb.Select("id").From("vacancies").Where(sb.In("city_id"))
+	a.NilError(sb.Validate())
+
+	setTestEmptyListPolicy(t, EmptyListError)
+
+	a.Equal(sb.Validate(), ErrEmptyList)
+
+	sb = NewSelectBuilder()
+	sb.Select("id").From("vacancies").Where(sb.In("city_id", 1))
+	a.NilError(sb.Validate())
+
+	sb.Where("company_id IN (" + sb.Var(List([]int{})) + ")")
+	a.Equal(sb.Validate(), ErrEmptyList)
+
+	nested := NewSelectBuilder()
+	nested.Select("id").From("companies").Where(nested.NotIn("region_id"))
+	sb = NewSelectBuilder()
+	sb.Select("id").From("vacancies").Where(sb.In("company_id", nested))
+	a.Equal(sb.Validate(), ErrEmptyList)
+
+	ub := NewUpdateBuilder()
+	ub.Update("vacancies").Set(ub.Assign("status", 0)).Where(ub.In("id"))
+	a.Equal(ub.Validate(), ErrEmptyList)
+
+	db := NewDeleteBuilder()
+	db.DeleteFrom("vacancies").Where(db.In("id", 1, 2))
+	a.NilError(db.Validate())
+}
+
+func TestEmptyListField(t *testing.T) {
+	a := assert.New(t)
+	setTestEmptyListPolicy(t, EmptyListKeep)
+
+	sb := NewSelectBuilder()
+	sb.Select("id").From("vacancies").Where(sb.In("a$b"), sb.NotIn("c$d"))
+	a.Equal(sb.String(), "SELECT id FROM vacancies WHERE a$b IN () AND c$d NOT IN ()")
+}
+
+func TestEmptyListValidateWithFlavor(t *testing.T) {
+	a := assert.New(t)
+	setTestEmptyListPolicy(t, EmptyListError)
+
+	// Another flavor with its own settings.
+	flavor := Flavor(100)
+	flavor.SetEmptyListPolicy(EmptyListMatchNone)
+
+	nested := NewSelectBuilder()
+	nested.Select("id").From("companies").Where(nested.In("region_id"))
+	sb := NewSelectBuilder()
+	sb.Select("id").From("vacancies").Where(sb.In("company_id", nested))
+
+	// Validation follows the flavor the query is built with.
+	s, _ := sb.BuildWithFlavor(flavor)
+	a.Equal(s, "SELECT id FROM vacancies WHERE company_id IN (SELECT id FROM companies WHERE 1=0)")
+	a.NilError(sb.ValidateWithFlavor(flavor))
+	a.Equal(sb.Validate(), ErrEmptyList)
+}
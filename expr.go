@@ -0,0 +1,407 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"fmt"
+)
+
+// Expr is a node of a condition expression tree.
+//
+// Unlike strings returned by `Cond`, an expression tree can be inspected by `Walk`
+// and transformed by `Prune` before it's rendered by `Cond#Expr`.
+// The tree is immutable, so it can be shared and reused freely.
+type Expr interface {
+	fmt.Stringer
+
+	// render adds the values of the expression to c.Args and returns the expression in builder's format.
+	render(c *Cond) string
+}
+
+// CompareOp is an operator of a ComparisonExpr.
+type CompareOp string
+
+// CompareOp enum
+const (
+	OpEqual        CompareOp = "="
+	OpNotEqual     CompareOp = "<>"
+	OpGreater      CompareOp = ">"
+	OpGreaterEqual CompareOp = ">="
+	OpLess         CompareOp = "<"
+	OpLessEqual    CompareOp = "<="
+	OpLike         CompareOp = "LIKE"
+	OpNotLike      CompareOp = "NOT LIKE"
+)
+
+// AndExpr represents "expr1 AND expr2 AND ...".
+type AndExpr []Expr
+
+// OrExpr represents "expr1 OR expr2 OR ...".
+type OrExpr []Expr
+
+// NotExpr represents "NOT expr".
+type NotExpr struct {
+	Expr Expr
+}
+
+// ComparisonExpr represents "field op value".
+type ComparisonExpr struct {
+	Field string
+	Op    CompareOp
+	Value interface{}
+}
+
+// InExpr represents "field IN (values...)" or "field NOT IN (values...)".
+type InExpr struct {
+	Field  string
+	Values []interface{}
+	Not    bool
+}
+
+// BetweenExpr represents "field BETWEEN lower AND upper" or "field NOT BETWEEN lower AND upper".
+type BetweenExpr struct {
+	Field string
+	Lower interface{}
+	Upper interface{}
+	Not   bool
+}
+
+// MatchExpr represents "MATCH('query')".
+type MatchExpr struct {
+	Query string
+}
+
+// RawExpr is an arbitrary SQL expression in builder's format, e.g. a string returned by `Cond`.
+type RawExpr string
+
+// And creates an AndExpr.
+func And(expr ...Expr) AndExpr {
+	return AndExpr(expr)
+}
+
+// Or creates an OrExpr.
+func Or(expr ...Expr) OrExpr {
+	return OrExpr(expr)
+}
+
+// Not creates a NotExpr.
+func Not(expr Expr) NotExpr {
+	return NotExpr{Expr: expr}
+}
+
+// Compare creates a ComparisonExpr.
+func Compare(field string, op CompareOp, value interface{}) ComparisonExpr {
+	return ComparisonExpr{Field: field, Op: op, Value: value}
+}
+
+// In creates an InExpr with IN operator.
+func In(field string, value ...interface{}) InExpr {
+	return InExpr{Field: field, Values: value}
+}
+
+// NotIn creates an InExpr with NOT IN operator.
+func NotIn(field string, value ...interface{}) InExpr {
+	return InExpr{Field: field, Values: value, Not: true}
+}
+
+// Between creates a BetweenExpr with BETWEEN operator.
+func Between(field string, lower, upper interface{}) BetweenExpr {
+	return BetweenExpr{Field: field, Lower: lower, Upper: upper}
+}
+
+// Match creates a MatchExpr.
+func Match(query string) MatchExpr {
+	return MatchExpr{Query: query}
+}
+
+func (e AndExpr) render(c *Cond) string {
+	return renderJoined(c, e, c.And)
+}
+
+func (e OrExpr) render(c *Cond) string {
+	return renderJoined(c, e, c.Or)
+}
+
+func renderJoined(c *Cond, exprs []Expr, join func(expr ...string) string) string {
+	rendered := make([]string, 0, len(exprs))
+
+	for _, expr := range exprs {
+		if expr == nil {
+			continue
+		}
+
+		if s := expr.render(c); s != "" {
+			rendered = append(rendered, s)
+		}
+	}
+
+	switch len(rendered) {
+	case 0:
+		return ""
+	case 1:
+		return rendered[0]
+	}
+
+	return join(rendered...)
+}
+
+func (e NotExpr) render(c *Cond) string {
+	if e.Expr == nil {
+		return ""
+	}
+
+	if negated, ok := negate(e.Expr); ok {
+		return negated.render(c)
+	}
+
+	s := e.Expr.render(c)
+
+	if s == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("NOT (%s)", s)
+}
+
+var negatedCompareOps = map[CompareOp]CompareOp{
+	OpEqual:        OpNotEqual,
+	OpNotEqual:     OpEqual,
+	OpGreater:      OpLessEqual,
+	OpGreaterEqual: OpLess,
+	OpLess:         OpGreaterEqual,
+	OpLessEqual:    OpGreater,
+	OpLike:         OpNotLike,
+	OpNotLike:      OpLike,
+}
+
+// negate returns an expression equal to "NOT expr" without the NOT operator,
+// which SphinxQL supports only in a few places.
+// It returns false if expr cannot be rewritten, e.g. for MATCH or a raw expression.
+func negate(expr Expr) (Expr, bool) {
+	switch e := expr.(type) {
+	case ComparisonExpr:
+		op, ok := negatedCompareOps[e.Op]
+
+		if !ok {
+			return nil, false
+		}
+
+		e.Op = op
+		return e, true
+	case InExpr:
+		e.Not = !e.Not
+		return e, true
+	case BetweenExpr:
+		e.Not = !e.Not
+		return e, true
+	case NotExpr:
+		if e.Expr == nil {
+			return nil, false
+		}
+
+		return e.Expr, true
+	case AndExpr:
+		// NOT (a AND b) = NOT a OR NOT b
+		negated := make(OrExpr, 0, len(e))
+
+		for _, child := range e {
+			if child != nil {
+				negated = append(negated, Not(child))
+			}
+		}
+
+		return negated, true
+	case OrExpr:
+		// NOT (a OR b) = NOT a AND NOT b
+		negated := make(AndExpr, 0, len(e))
+
+		for _, child := range e {
+			if child != nil {
+				negated = append(negated, Not(child))
+			}
+		}
+
+		return negated, true
+	}
+
+	return nil, false
+}
+
+func (e ComparisonExpr) render(c *Cond) string {
+	return fmt.Sprintf("%s %s %s", Escape(e.Field), e.Op, c.Args.Add(e.Value))
+}
+
+func (e InExpr) render(c *Cond) string {
+	if e.Not {
+		return c.NotIn(e.Field, e.Values...)
+	}
+
+	return c.In(e.Field, e.Values...)
+}
+
+func (e BetweenExpr) render(c *Cond) string {
+	if e.Not {
+		return c.NotBetween(e.Field, e.Lower, e.Upper)
+	}
+
+	return c.Between(e.Field, e.Lower, e.Upper)
+}
+
+func (e MatchExpr) render(c *Cond) string {
+	return c.Match(e.Query)
+}
+
+func (e RawExpr) render(c *Cond) string {
+	return string(e)
+}
+
+// String returns the expression with interpolated values. It's designed for logging.
+func (e AndExpr) String() string { return exprString(e) }
+
+// String returns the expression with interpolated values. It's designed for logging.
+func (e OrExpr) String() string { return exprString(e) }
+
+// String returns the expression with interpolated values. It's designed for logging.
+func (e NotExpr) String() string { return exprString(e) }
+
+// String returns the expression with interpolated values. It's designed for logging.
+func (e ComparisonExpr) String() string { return exprString(e) }
+
+// String returns the expression with interpolated values. It's designed for logging.
+func (e InExpr) String() string { return exprString(e) }
+
+// String returns the expression with interpolated values. It's designed for logging.
+func (e BetweenExpr) String() string { return exprString(e) }
+
+// String returns the expression with interpolated values. It's designed for logging.
+func (e MatchExpr) String() string { return exprString(e) }
+
+// String returns the raw expression.
+func (e RawExpr) String() string { return string(e) }
+
+// exprString renders e with DefaultFlavor and interpolates its values.
+// If the values cannot be interpolated, the placeholders are kept.
+func exprString(e Expr) string {
+	c := &Cond{
+		Args: &Args{},
+	}
+	sql, args := c.Args.CompileWithFlavor(e.render(c), DefaultFlavor)
+
+	if s, err := DefaultFlavor.Interpolate(sql, args); err == nil {
+		return s
+	}
+
+	return sql
+}
+
+// Expr renders an expression tree and returns it as a string, which can be used
+// in other `Cond` methods like `Cond#Or` or in builders without `WhereExpr`.
+// An empty string is returned for a nil or empty expression.
+func (c *Cond) Expr(expr Expr) string {
+	if expr == nil {
+		return ""
+	}
+
+	return expr.render(c)
+}
+
+// Walk traverses expr in depth-first order and calls fn for every node.
+// If fn returns false, the children of the node are skipped.
+func Walk(expr Expr, fn func(expr Expr) bool) {
+	if expr == nil || !fn(expr) {
+		return
+	}
+
+	switch e := expr.(type) {
+	case AndExpr:
+		for _, child := range e {
+			Walk(child, fn)
+		}
+	case OrExpr:
+		for _, child := range e {
+			Walk(child, fn)
+		}
+	case NotExpr:
+		Walk(e.Expr, fn)
+	}
+}
+
+// Prune returns a copy of expr without the nodes for which drop returns true.
+// AND, OR and NOT nodes which become empty are removed too.
+// Prune returns nil if nothing is left.
+//
+// It's handy to exclude the filter of a facet from its own count query.
+func Prune(expr Expr, drop func(expr Expr) bool) Expr {
+	if expr == nil || drop(expr) {
+		return nil
+	}
+
+	switch e := expr.(type) {
+	case AndExpr:
+		if pruned := pruneAll(e, drop); len(pruned) > 0 {
+			return AndExpr(pruned)
+		}
+
+		return nil
+	case OrExpr:
+		if pruned := pruneAll(e, drop); len(pruned) > 0 {
+			return OrExpr(pruned)
+		}
+
+		return nil
+	case NotExpr:
+		if child := Prune(e.Expr, drop); child != nil {
+			return NotExpr{Expr: child}
+		}
+
+		return nil
+	}
+
+	return expr
+}
+
+func pruneAll(exprs []Expr, drop func(expr Expr) bool) []Expr {
+	pruned := make([]Expr, 0, len(exprs))
+
+	for _, expr := range exprs {
+		if p := Prune(expr, drop); p != nil {
+			pruned = append(pruned, p)
+		}
+	}
+
+	return pruned
+}
+
+// WhereExpr adds expression trees to WHERE in SELECT.
+// They are joined with other expressions of WHERE by AND, and empty trees are skipped.
+func (sb *SelectBuilder) WhereExpr(expr ...Expr) *SelectBuilder {
+	return sb.Where(sb.renderExprs(expr)...)
+}
+
+// HavingExpr adds expression trees to HAVING in SELECT.
+// They are joined with other expressions of HAVING by AND, and empty trees are skipped.
+func (sb *SelectBuilder) HavingExpr(expr ...Expr) *SelectBuilder {
+	return sb.Having(sb.renderExprs(expr)...)
+}
+
+func (sb *SelectBuilder) renderExprs(exprs []Expr) []string {
+	rendered := make([]string, 0, len(exprs))
+
+	for _, expr := range exprs {
+		rendered = append(rendered, sb.Expr(expr))
+	}
+
+	return rendered
+}
+
+func appendNonEmpty(exprs []string, expr []string) []string {
+	for _, e := range expr {
+		if e != "" {
+			exprs = append(exprs, e)
+		}
+	}
+
+	return exprs
+}
@@ -0,0 +1,163 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func ExampleSelectBuilder_WhereExpr() {
+	filters := And(
+		Match("golang"),
+		In("city_id", 1, 2),
+		Compare("salary", OpGreaterEqual, 100000),
+		Or(
+			Compare("remote", OpEqual, 1),
+			Between("experience", 1, 3),
+		),
+	)
+
+	// Count facet values without the facet's own filter.
+	facet := Prune(filters, func(e Expr) bool {
+		in, ok := e.(InExpr)
+		return ok && in.Field == "city_id"
+	})
+
+	sb := NewSelectBuilder()
+	sb.Select("city_id", sb.As(sb.Count(""), "cnt"))
+	sb.From("vacancies")
+	sb.WhereExpr(facet)
+	sb.GroupBy("city_id")
+
+	s, args := sb.Build()
+	fmt.Println(s)
+	fmt.Println(args)
+	fmt.Println(filters)
+
+	// Output:
+	// SELECT city_id, COUNT(*) AS cnt FROM vacancies WHERE (MATCH(?) AND salary >= ? AND (remote = ? OR experience BETWEEN ? AND ?)) GROUP BY city_id
+	// [golang 100000 1 1 3]
+	// (MATCH('golang') AND city_id IN (1, 2) AND salary >= 100000 AND (remote = 1 OR experience BETWEEN 1 AND 3))
+}
+
+func TestExpr(t *testing.T) {
+	a := assert.New(t)
+	cases := map[string]Expr{
+		"$$a = $0":                     Compare("$a", OpEqual, 1),
+		"a NOT LIKE $0":                Compare("a", OpNotLike, "%x%"),
+		"a IN ($0, $1)":                In("a", 1, 2),
+		"a NOT IN ($0, $1)":            NotIn("a", 1, 2),
+		"a BETWEEN $0 AND $1":          Between("a", 1, 2),
+		"a NOT BETWEEN $0 AND $1":      BetweenExpr{Field: "a", Lower: 1, Upper: 2, Not: true},
+		"MATCH($0)":                    Match("x"),
+		"a > 1":                        RawExpr("a > 1"),
+		"NOT (a > 1)":                  Not(RawExpr("a > 1")),
+		"(a > 1 AND b > 2)":            And(RawExpr("a > 1"), nil, RawExpr("b > 2")),
+		"(a > 1 OR (b > 2 AND c > 3))": Or(RawExpr("a > 1"), And(RawExpr("b > 2"), RawExpr("c > 3"))),
+		"a >= 1":                       And(Or(RawExpr("a >= 1"))),
+		"":                             And(Or(), Not(And())),
+	}
+
+	for expected, expr := range cases {
+		a.Use(&expected, &expr)
+		a.Equal(newTestCond().Expr(expr), expected)
+	}
+
+	a.Equal(newTestCond().Expr(nil), "")
+}
+
+func TestWalk(t *testing.T) {
+	a := assert.New(t)
+	expr := And(Match("x"), Or(In("a", 1), Not(Compare("b", OpEqual, 2))))
+	var fields []string
+
+	Walk(expr, func(e Expr) bool {
+		switch n := e.(type) {
+		case InExpr:
+			fields = append(fields, n.Field)
+		case ComparisonExpr:
+			fields = append(fields, n.Field)
+		case NotExpr:
+			return false
+		}
+
+		return true
+	})
+
+	a.Equal(fields, []string{"a"})
+}
+
+func TestPrune(t *testing.T) {
+	a := assert.New(t)
+	expr := And(Match("x"), Or(In("a", 1), Not(Compare("b", OpEqual, 2))), Compare("c", OpLess, 3))
+	dropField := func(field string) func(e Expr) bool {
+		return func(e Expr) bool {
+			switch n := e.(type) {
+			case InExpr:
+				return n.Field == field
+			case ComparisonExpr:
+				return n.Field == field
+			}
+
+			return false
+		}
+	}
+
+	a.Equal(Prune(expr, dropField("a")), And(Match("x"), Or(Not(Compare("b", OpEqual, 2))), Compare("c", OpLess, 3)))
+	a.Equal(Prune(expr, dropField("b")), And(Match("x"), Or(In("a", 1)), Compare("c", OpLess, 3)))
+	a.Equal(Prune(Or(In("a", 1)), dropField("a")), nil)
+	a.Equal(Prune(expr, func(Expr) bool { return true }), nil)
+
+	// The original tree is kept untouched.
+	a.Equal(expr.String(), "(MATCH('x') AND (a IN (1) OR b <> 2) AND c < 3)")
+}
+
+func TestSelectBuilderExpr(t *testing.T) {
+	a := assert.New(t)
+	sb := NewSelectBuilder()
+	sb.Select("company_id", sb.As(sb.Count(""), "cnt")).From("vacancies")
+	sb.Where("status = 1")
+	sb.WhereExpr(In("city_id", 1), nil)
+	sb.WhereExpr(Prune(In("tag_id", 2), func(Expr) bool { return true }))
+	sb.Where(sb.Expr(Compare("salary", OpGreater, 100)))
+	sb.GroupBy("company_id")
+	sb.HavingExpr(Compare("cnt", OpGreater, 10))
+
+	s, args := sb.Build()
+	a.Equal(s, "SELECT company_id, COUNT(*) AS cnt FROM vacancies WHERE status = 1 AND city_id IN (?) AND salary > ? GROUP BY company_id HAVING cnt > ?")
+	a.Equal(args, []interface{}{1, 100, 10})
+}
+
+func TestSelectBuilderWhereExprExcludeSelf(t *testing.T) {
+	a := assert.New(t)
+	filters := And(
+		Match("golang"),
+		In("city_id", 1, 2),
+		In("company_id", 3),
+	)
+
+	// The facet of city_id counts documents without its own filter.
+	facet := NewSelectBuilder()
+	facet.Select("city_id", facet.As(facet.Count(""), "cnt")).From("vacancies")
+	facet.WhereExpr(Prune(filters, func(e Expr) bool {
+		in, ok := e.(InExpr)
+		return ok && in.Field == "city_id"
+	}))
+	facet.GroupBy("city_id")
+
+	s, args := facet.Build()
+	a.Equal(s, "SELECT city_id, COUNT(*) AS cnt FROM vacancies WHERE (MATCH(?) AND company_id IN (?)) GROUP BY city_id")
+	a.Equal(args, []interface{}{"golang", 3})
+
+	// The main query keeps all filters.
+	sb := NewSelectBuilder()
+	sb.Select("id").From("vacancies").WhereExpr(filters)
+	s, args = sb.Build()
+	a.Equal(s, "SELECT id FROM vacancies WHERE (MATCH(?) AND city_id IN (?, ?) AND company_id IN (?))")
+	a.Equal(args, []interface{}{"golang", 1, 2, 3})
+}
@@ -0,0 +1,404 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"hash/fnv"
+	"strings"
+)
+
+// Fingerprint builds b and returns the normalized shape of the query and its hash.
+// See `FingerprintSQL` for details.
+//
+// Hooks are not called, so Fingerprint can be used in a hook and
+// the shape is not affected by options or comments added by hooks.
+func Fingerprint(b Builder) (normalized string, hash uint64) {
+	sql, _ := BuildWithoutHooks(b)
+	return FingerprintSQL(sql)
+}
+
+// FingerprintSQL normalizes sql and returns its shape and a stable hash of the shape.
+// Queries which differ only in values have the same shape, so the hash is handy
+// to aggregate metrics by query.
+//
+// The normalization does following.
+//
+//   - String and number literals, including MATCH text, are replaced with "?".
+//     The sign of a negative number is replaced too, so "-5" and "5" have the same shape.
+//   - IN lists and value tuples are collapsed to "(?+)" regardless of their length.
+//   - Rows of a multi-row VALUES are collapsed to the first row.
+//   - Values of OPTION are replaced with "?". FACET clauses after OPTION are kept.
+//   - Sequences of white spaces are replaced with a single space.
+//
+// Identifiers and keywords are kept as is.
+func FingerprintSQL(sql string) (normalized string, hash uint64) {
+	normalized = normalizeLiterals(sql)
+	normalized = collapseLists(normalized)
+	normalized = collapseValues(normalized)
+	normalized = normalizeOptions(normalized)
+
+	h := fnv.New64a()
+	h.Write([]byte(normalized))
+	return normalized, h.Sum64()
+}
+
+// normalizeLiterals replaces all literals in sql with "?" and squeezes white spaces.
+func normalizeLiterals(sql string) string {
+	buf := &strings.Builder{}
+	buf.Grow(len(sql))
+	last := 0
+
+	scanQuery(sql, func(kind byte, start, end int) error {
+		writeNormalizedSegment(buf, sql[last:start])
+		last = end
+
+		if kind == '`' {
+			buf.WriteString(sql[start:end])
+		} else {
+			buf.WriteByte('?')
+		}
+
+		return nil
+	})
+
+	writeNormalizedSegment(buf, sql[last:])
+	return strings.TrimSpace(buf.String())
+}
+
+// writeNormalizedSegment writes an unquoted part of a query to buf
+// with numbers replaced with "?" and white spaces squeezed.
+func writeNormalizedSegment(buf *strings.Builder, s string) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case isSpaceByte(c):
+			for i+1 < len(s) && isSpaceByte(s[i+1]) {
+				i++
+			}
+
+			if buf.Len() > 0 && !strings.HasSuffix(buf.String(), " ") {
+				buf.WriteByte(' ')
+			}
+
+		case c == '-' && i+1 < len(s) && '0' <= s[i+1] && s[i+1] <= '9' && isUnaryContext(buf.String()):
+			// The sign of a negative number is folded into its placeholder.
+			continue
+
+		case isIdentByte(c):
+			start := i
+
+			for i+1 < len(s) && isNumberByte(s[start:i+2]) {
+				i++
+			}
+
+			if '0' <= c && c <= '9' && isNumberLiteral(s[start:i+1]) {
+				buf.WriteByte('?')
+				continue
+			}
+
+			buf.WriteString(s[start : i+1])
+
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
+
+// unaryKeywords are keywords which can be followed by a negative number.
+var unaryKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "BETWEEN": true, "SELECT": true,
+	"WHERE": true, "HAVING": true, "BY": true, "LIMIT": true, "OFFSET": true,
+}
+
+// isUnaryContext reports whether a "-" written after normalized is a sign rather than a subtraction.
+func isUnaryContext(normalized string) bool {
+	s := strings.TrimRight(normalized, " ")
+
+	if s == "" {
+		return true
+	}
+
+	c := s[len(s)-1]
+
+	if !isIdentByte(c) {
+		return strings.IndexByte("=<>!(,{+-*/%", c) >= 0
+	}
+
+	start := len(s) - 1
+
+	for start > 0 && isIdentByte(s[start-1]) {
+		start--
+	}
+
+	return unaryKeywords[strings.ToUpper(s[start:])]
+}
+
+// isNumberByte reports whether the last byte of token continues an identifier or a number like "1.5e-3".
+func isNumberByte(token string) bool {
+	c := token[len(token)-1]
+
+	if isIdentByte(c) {
+		return true
+	}
+
+	if !('0' <= token[0] && token[0] <= '9') {
+		return false
+	}
+
+	switch c {
+	case '.':
+		return true
+	case '+', '-':
+		prev := token[len(token)-2]
+		return (prev == 'e' || prev == 'E') && !strings.HasPrefix(token, "0x")
+	}
+
+	return false
+}
+
+func isNumberLiteral(s string) bool {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return true
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if !('0' <= c && c <= '9') && c != '.' && c != 'e' && c != 'E' && c != '+' && c != '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isSpaceByte(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// collapseLists replaces IN lists and tuples which contain only placeholders with "(?+)".
+// A single placeholder in parentheses is collapsed only after IN,
+// so that function calls like "MATCH(?)" are kept.
+func collapseLists(sql string) string {
+	buf := &strings.Builder{}
+	buf.Grow(len(sql))
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+
+		if c == '`' {
+			end := skipQuoted(sql, i)
+
+			if end >= len(sql) {
+				end = len(sql) - 1
+			}
+
+			buf.WriteString(sql[i : end+1])
+			i = end
+			continue
+		}
+
+		if c != '(' {
+			buf.WriteByte(c)
+			continue
+		}
+
+		end := strings.IndexByte(sql[i:], ')')
+
+		if end < 0 {
+			buf.WriteByte(c)
+			continue
+		}
+
+		end += i
+		items := strings.Split(sql[i+1:end], ",")
+		collapsible := len(items) > 1 || endsWithKeyword(buf.String(), "IN")
+
+		for _, item := range items {
+			if strings.TrimSpace(item) != "?" {
+				collapsible = false
+				break
+			}
+		}
+
+		if !collapsible {
+			buf.WriteByte(c)
+			continue
+		}
+
+		buf.WriteString("(?+)")
+		i = end
+	}
+
+	return buf.String()
+}
+
+// endsWithKeyword reports whether s ends with a keyword, which may be followed by a space.
+func endsWithKeyword(s, keyword string) bool {
+	s = strings.TrimSuffix(s, " ")
+
+	if len(s) < len(keyword) || !strings.EqualFold(s[len(s)-len(keyword):], keyword) {
+		return false
+	}
+
+	return len(s) == len(keyword) || !isIdentByte(s[len(s)-len(keyword)-1])
+}
+
+// collapseValues keeps only the first row of a multi-row VALUES.
+func collapseValues(sql string) string {
+	idx := indexKeyword(sql, "VALUES")
+
+	if idx < 0 {
+		return sql
+	}
+
+	i := idx + len("VALUES")
+
+	for i < len(sql) && sql[i] == ' ' {
+		i++
+	}
+
+	end := matchParen(sql, i)
+
+	if end < 0 {
+		return sql
+	}
+
+	rest := sql[end+1:]
+
+	for {
+		next := strings.TrimLeft(rest, " ")
+
+		if !strings.HasPrefix(next, ",") {
+			break
+		}
+
+		next = strings.TrimLeft(next[1:], " ")
+		rowEnd := matchParen(next, 0)
+
+		if rowEnd < 0 {
+			break
+		}
+
+		rest = next[rowEnd+1:]
+	}
+
+	return sql[:end+1] + rest
+}
+
+// normalizeOptions replaces the values of OPTION with "?".
+// The OPTION clause ends at the end of sql or at a FACET clause following it.
+func normalizeOptions(sql string) string {
+	idx := indexKeyword(sql, "OPTION")
+
+	if idx < 0 {
+		return sql
+	}
+
+	start := idx + len("OPTION")
+	end := len(sql)
+	rest := ""
+
+	if facet := indexKeyword(sql[start:], "FACET"); facet >= 0 {
+		end = start + facet
+		rest = " " + sql[end:]
+	}
+
+	items := splitTopLevel(sql[start:end])
+
+	for i, item := range items {
+		eq := strings.IndexByte(item, '=')
+
+		if eq < 0 {
+			continue
+		}
+
+		items[i] = strings.TrimRight(item[:eq], " ") + " = ?"
+	}
+
+	return sql[:start] + strings.Join(items, ",") + rest
+}
+
+// indexKeyword returns the index of the first keyword outside of parentheses and quotes in s,
+// or -1 if it's not found.
+func indexKeyword(s, keyword string) int {
+	depth := 0
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(s, i)
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && isIdentByte(c):
+			start := i
+
+			for i+1 < len(s) && isIdentByte(s[i+1]) {
+				i++
+			}
+
+			if strings.EqualFold(s[start:i+1], keyword) {
+				return start
+			}
+		}
+	}
+
+	return -1
+}
+
+// matchParen returns the index of the parenthesis closing the one at s[start],
+// or -1 if s[start] is not an opening parenthesis or it's not closed.
+func matchParen(s string, start int) int {
+	if start >= len(s) || s[start] != '(' {
+		return -1
+	}
+
+	depth := 0
+
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '`':
+			i = skipQuoted(s, i)
+		case '(':
+			depth++
+		case ')':
+			depth--
+
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// splitTopLevel splits s by commas outside of parentheses and backquotes.
+func splitTopLevel(s string) []string {
+	var items []string
+	depth := 0
+	last := 0
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '`':
+			i = skipQuoted(s, i)
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+
+	return append(items, s[last:])
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func ExampleFingerprint() {
+	search := func(query string, cities ...interface{}) Builder {
+		sb := NewSelectBuilder()
+		sb.Select("id").From("vacancies")
+		sb.Where(sb.Match(query), sb.In("city_id", cities...))
+		sb.Limit(20)
+		return sb
+	}
+
+	s1, h1 := Fingerprint(search("golang", 1, 2))
+	s2, h2 := Fingerprint(search("php developer", 3))
+
+	fmt.Println(s1)
+	fmt.Println(s1 == s2, h1 == h2)
+
+	// Output:
+	// SELECT id FROM vacancies WHERE MATCH(?) AND city_id IN (?+) LIMIT ?
+	// true true
+}
+
+func TestFingerprintSQL(t *testing.T) {
+	a := assert.New(t)
+	cases := map[string]string{
+		"SELECT * FROM idx WHERE MATCH('hello \\'world\\'') AND a = 1":                                             "SELECT * FROM idx WHERE MATCH(?) AND a = ?",
+		"SELECT * FROM idx WHERE a IN (1, 2, 3) AND b IN ('x') AND c NOT IN (?, ?)":                                "SELECT * FROM idx WHERE a IN (?+) AND b IN (?+) AND c NOT IN (?+)",
+		"SELECT * FROM idx WHERE MATCH(?) AND f(1)":                                                                "SELECT * FROM idx WHERE MATCH(?) AND f(?)",
+		"SELECT  id,\n\tw2  FROM `idx 1` WHERE x > 1.5e-3 AND y = 0x1F LIMIT 10, 20":                               "SELECT id, w2 FROM `idx 1` WHERE x > ? AND y = ? LIMIT ?, ?",
+		"SELECT id FROM idx WHERE e-1 > 0 OPTION max_matches = 1000, ranker = bm25":                                "SELECT id FROM idx WHERE e-? > ? OPTION max_matches = ?, ranker = ?",
+		"SELECT id FROM idx OPTION field_weights = (title = 10, body = 3), comment = 'x'":                          "SELECT id FROM idx OPTION field_weights = ?, comment = ?",
+		"INSERT INTO idx (id, title, tags) VALUES (1, 'a', (1, 2)), (2, 'b', (3)), (3, 'c', ())":                   "INSERT INTO idx (id, title, tags) VALUES (?, ?, (?+))",
+		"INSERT INTO idx (id) VALUES (1), (2)":                                                                     "INSERT INTO idx (id) VALUES (?)",
+		"UPDATE idx SET `a?` = 'b?' WHERE id = 1":                                                                  "UPDATE idx SET `a?` = ? WHERE id = ?",
+		"SELECT id FROM idx WHERE x = -5 AND y IN (-1, 2) AND z BETWEEN -3 AND -1.5 AND w > 1 - 2":                 "SELECT id FROM idx WHERE x = ? AND y IN (?+) AND z BETWEEN ? AND ? AND w > ? - ?",
+		"SELECT -1, a-1 FROM idx":                                                                                  "SELECT ?, a-? FROM idx",
+		"SELECT id FROM idx OPTION ranker=bm25 FACET brand_id":                                                     "SELECT id FROM idx OPTION ranker = ? FACET brand_id",
+		"SELECT id FROM idx OPTION max_matches=10, ranker=expr('sum(lcs)') FACET a ORDER BY COUNT(*) DESC FACET b": "SELECT id FROM idx OPTION max_matches = ?, ranker = ? FACET a ORDER BY COUNT(*) DESC FACET b",
+	}
+
+	for sql, expected := range cases {
+		a.Use(&sql, &expected)
+		actual, _ := FingerprintSQL(sql)
+		a.Equal(actual, expected)
+	}
+}
+
+func TestFingerprintHash(t *testing.T) {
+	a := assert.New(t)
+	_, h1 := FingerprintSQL("SELECT * FROM idx WHERE a IN (1, 2) OPTION max_matches = 10")
+	_, h2 := FingerprintSQL("SELECT *  FROM idx WHERE a IN (3) OPTION max_matches = 2000")
+	_, h3 := FingerprintSQL("SELECT * FROM idx WHERE b IN (3) OPTION max_matches = 2000")
+
+	a.Equal(h1, h2)
+	a.NotEqual(h1, h3)
+
+	_, h1 = FingerprintSQL("SELECT * FROM idx WHERE x = -5")
+	_, h2 = FingerprintSQL("SELECT * FROM idx WHERE x = 5")
+	a.Equal(h1, h2)
+
+	_, h1 = FingerprintSQL("SELECT * FROM idx OPTION ranker = bm25 FACET a")
+	_, h2 = FingerprintSQL("SELECT * FROM idx OPTION ranker = bm25 FACET b")
+	_, h3 = FingerprintSQL("SELECT * FROM idx OPTION ranker = bm25")
+	a.NotEqual(h1, h2)
+	a.NotEqual(h1, h3)
+}
+
+func TestFingerprintWithoutHooks(t *testing.T) {
+	a := assert.New(t)
+	sb := NewSelectBuilder()
+	sb.Select("id").From("idx").Where(sb.Equal("a", 1))
+	expected, expectedHash := Fingerprint(sb)
+
+	var normalized []string
+	AddHook(func(q *Query) {
+		q.AddOption(q.Opt().Comment("trace_id=42"))
+
+		// Fingerprint doesn't call the hook recursively.
+		n, _ := Fingerprint(q.Builder)
+		normalized = append(normalized, n)
+	})
+	t.Cleanup(ResetHooks)
+
+	actual, hash := Fingerprint(sb)
+	a.Equal(actual, expected)
+	a.Equal(hash, expectedHash)
+	a.Equal(len(normalized), 0)
+
+	sb.Build()
+	a.Equal(normalized, []string{"SELECT id FROM idx WHERE a = ?"})
+}
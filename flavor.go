@@ -7,6 +7,7 @@ package sphinxql
 import (
 	"errors"
 	"fmt"
+	"sync"
 )
 
 // Supported flavors.
@@ -36,6 +37,48 @@ var (
 // Flavor is the flag to control the format of compiled sql.
 type Flavor int
 
+// flavorSettings keeps settings which can be customized per flavor.
+type flavorSettings struct {
+	maxMatchesPolicy MaxMatchesPolicy
+	emptyListPolicy  EmptyListPolicy
+	hooks            []Hook
+	timeEncoding     TimeEncoding
+	floatPolicy      FloatPolicy
+	inSliceChunkSize int
+}
+
+var (
+	flavorSettingsMu  sync.RWMutex
+	flavorSettingsMap = map[Flavor]*flavorSettings{}
+)
+
+// settings returns a copy of settings of f.
+func (f Flavor) settings() flavorSettings {
+	flavorSettingsMu.RLock()
+	defer flavorSettingsMu.RUnlock()
+
+	if fs, ok := flavorSettingsMap[f]; ok {
+		return *fs
+	}
+
+	return flavorSettings{}
+}
+
+// updateSettings calls update with settings of f under lock.
+func (f Flavor) updateSettings(update func(fs *flavorSettings)) {
+	flavorSettingsMu.Lock()
+	defer flavorSettingsMu.Unlock()
+
+	fs, ok := flavorSettingsMap[f]
+
+	if !ok {
+		fs = &flavorSettings{}
+		flavorSettingsMap[f] = fs
+	}
+
+	update(fs)
+}
+
 // String returns the name of f.
 func (f Flavor) String() string {
 	switch f {
@@ -100,3 +143,12 @@ func (f Flavor) Quote(name string) string {
 
 	return name
 }
+
+// QuoteString quotes s as a string literal in the same way as `Flavor#Interpolate`.
+//
+//   - For SphinxSearch, use the unescaping rules of searchd: "'" and "\" are escaped,
+//     "\x00", "\b", "\n", "\r" and "\t" are written as escape sequences and other bytes are kept.
+//   - Other flavors quote strings like SphinxSearch.
+func (f Flavor) QuoteString(s string) string {
+	return string(quoteStringValue(nil, s, f))
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ErrInterpolateInvalidFloat means that a float is NaN or infinity, which the searchd refuses.
+// The error returned by interpolation is a *FloatError wrapping it.
+var ErrInterpolateInvalidFloat = errors.New("go-sphinxql: NaN or infinity cannot be interpolated")
+
+// FloatError is returned by interpolation for a float which cannot be written in SphinxQL.
+type FloatError struct {
+	Value float64
+}
+
+// Error returns the error message with the value.
+func (e *FloatError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrInterpolateInvalidFloat, e.Value)
+}
+
+// Unwrap returns ErrInterpolateInvalidFloat.
+func (e *FloatError) Unwrap() error {
+	return ErrInterpolateInvalidFloat
+}
+
+// FloatPolicy controls how floats are interpolated.
+//
+// By default, floats are written in decimal notation with the shortest representation
+// which reads back exactly, e.g. 1e21 is written as "1000000000000000000000".
+// NaN and infinities are always rejected with a *FloatError.
+type FloatPolicy struct {
+	// Exponent allows exponent notation like "1e+21" for very large and small values.
+	// MaxDecimals is ignored if Exponent is set.
+	Exponent bool
+
+	// MaxDecimals caps the number of digits after the decimal point.
+	// Extra digits are rounded. Zero means no cap.
+	MaxDecimals int
+}
+
+// SetFloatPolicy sets the policy of interpolating floats with flavor f.
+//
+// SetFloatPolicy is expected to be called once during initialization.
+func (f Flavor) SetFloatPolicy(policy FloatPolicy) {
+	f.updateSettings(func(fs *flavorSettings) {
+		fs.floatPolicy = policy
+	})
+}
+
+// FloatPolicy returns the policy of interpolating floats with flavor f.
+func (f Flavor) FloatPolicy() FloatPolicy {
+	return f.settings().floatPolicy
+}
+
+// appendFloat appends v formatted according to policy to buf.
+// The bitSize is 32 for float32 and 64 for float64.
+func appendFloat(buf []byte, v float64, bitSize int, policy FloatPolicy) ([]byte, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, &FloatError{Value: v}
+	}
+
+	if policy.Exponent {
+		return strconv.AppendFloat(buf, v, 'g', -1, bitSize), nil
+	}
+
+	if policy.MaxDecimals <= 0 {
+		return strconv.AppendFloat(buf, v, 'f', -1, bitSize), nil
+	}
+
+	start := len(buf)
+	buf = strconv.AppendFloat(buf, v, 'f', policy.MaxDecimals, bitSize)
+
+	// Trim trailing zeros of the fixed precision, e.g. "1.500" to "1.5".
+	end := len(buf)
+
+	for buf[end-1] == '0' {
+		end--
+	}
+
+	if buf[end-1] == '.' {
+		end--
+	}
+
+	buf = buf[:end]
+
+	// A small negative value can be rounded to "-0".
+	if string(buf[start:]) == "-0" {
+		buf = append(buf[:start], '0')
+	}
+
+	return buf, nil
+}
+
+func appendFloatVector(buf []byte, v []float32, policy FloatPolicy) ([]byte, error) {
+	var err error
+	buf = append(buf, '(')
+
+	for i, f := range v {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+
+		if buf, err = appendFloat(buf, float64(f), 32, policy); err != nil {
+			return nil, err
+		}
+	}
+
+	buf = append(buf, ')')
+	return buf, nil
+}
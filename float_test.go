@@ -0,0 +1,79 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func setTestFloatPolicy(t *testing.T, policy FloatPolicy) {
+	old := SphinxSearch.FloatPolicy()
+	SphinxSearch.SetFloatPolicy(policy)
+	t.Cleanup(func() {
+		SphinxSearch.SetFloatPolicy(old)
+	})
+}
+
+func ExampleFlavor_SetFloatPolicy() {
+	SphinxSearch.SetFloatPolicy(FloatPolicy{MaxDecimals: 6})
+	defer SphinxSearch.SetFloatPolicy(FloatPolicy{})
+
+	sb := NewSelectBuilder()
+	sb.Select("id").From("vacancies")
+	sb.Where(sb.LessEqualThan("salary", 1e21))
+	sb.Where(sb.Equal("lat", 55.75582600000001))
+	s, args := sb.Build()
+
+	query, err := SphinxSearch.Interpolate(s, args)
+	fmt.Println(query, err)
+
+	_, err = SphinxSearch.Interpolate(s, []interface{}{math.NaN(), 1})
+	fmt.Println(err)
+
+	// Output:
+	// SELECT id FROM vacancies WHERE salary <= 1000000000000000000000 AND lat = 55.755826 <nil>
+	// go-sphinxql: NaN or infinity cannot be interpolated: NaN
+}
+
+func TestInterpolateFloat(t *testing.T) {
+	a := assert.New(t)
+	cases := []struct {
+		policy   FloatPolicy
+		args     []interface{}
+		expected string
+	}{
+		{FloatPolicy{}, []interface{}{1e21, 3e-5, float32(0.1), -2.0}, "SELECT 1000000000000000000000, 0.00003, 0.1, -2"},
+		{FloatPolicy{Exponent: true}, []interface{}{1e21, 3e-5, float32(0.1), -2.0}, "SELECT 1e+21, 3e-05, 0.1, -2"},
+		{FloatPolicy{MaxDecimals: 2}, []interface{}{1.005, 1.5, -0.001, 3.0}, "SELECT 1, 1.5, 0, 3"},
+		{FloatPolicy{MaxDecimals: 3}, []interface{}{37.61729, FloatVector{0.12345, -1}, float32(2.5), 1e21}, "SELECT 37.617, (0.123, -1), 2.5, 1000000000000000000000"},
+	}
+
+	for _, c := range cases {
+		a.Use(&c)
+		setTestFloatPolicy(t, c.policy)
+
+		query, err := SphinxSearch.Interpolate("SELECT ?, ?, ?, ?", c.args)
+		a.NilError(err)
+		a.Equal(query, c.expected)
+	}
+}
+
+func TestInterpolateInvalidFloat(t *testing.T) {
+	a := assert.New(t)
+
+	for _, v := range []interface{}{math.NaN(), math.Inf(1), float32(math.Inf(-1)), FloatVector{1, float32(math.NaN())}} {
+		a.Use(&v)
+		_, err := SphinxSearch.Interpolate("SELECT ?", []interface{}{v})
+		a.Assert(errors.Is(err, ErrInterpolateInvalidFloat))
+
+		var fe *FloatError
+		a.Assert(errors.As(err, &fe))
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GeoDistUnit is a unit of angles or distances in GEODIST options.
+type GeoDistUnit string
+
+// GeoDistUnit enum
+const (
+	GeoDistDegrees    GeoDistUnit = "deg"
+	GeoDistRadians    GeoDistUnit = "rad"
+	GeoDistMeters     GeoDistUnit = "m"
+	GeoDistKilometers GeoDistUnit = "km"
+	GeoDistFeet       GeoDistUnit = "ft"
+	GeoDistMiles      GeoDistUnit = "mi"
+)
+
+// GeoDistMethod is a distance calculation method in GEODIST options.
+type GeoDistMethod string
+
+// GeoDistMethod enum
+const (
+	GeoDistHaversine GeoDistMethod = "haversine"
+	GeoDistAdaptive  GeoDistMethod = "adaptive"
+)
+
+// GeoDistOptions represents the options map of GEODIST.
+// Empty fields are omitted, so the server defaults are used for them.
+type GeoDistOptions struct {
+	In     GeoDistUnit
+	Out    GeoDistUnit
+	Method GeoDistMethod
+}
+
+// String returns the options map, e.g. "{in=deg, out=km, method=adaptive}".
+// It returns an empty string if no option is set.
+func (o GeoDistOptions) String() string {
+	var opts []string
+
+	if o.In != "" {
+		opts = append(opts, "in="+string(o.In))
+	}
+
+	if o.Out != "" {
+		opts = append(opts, "out="+string(o.Out))
+	}
+
+	if o.Method != "" {
+		opts = append(opts, "method="+string(o.Method))
+	}
+
+	if len(opts) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(opts, ", "))
+}
+
+// Point is a point on a plane used in POLY2D.
+type Point struct {
+	X, Y float64
+}
+
+// GeoPoint is a point on the Earth used in GEOPOLY2D.
+type GeoPoint struct {
+	Lat, Lon float64
+}
+
+// GeoDist represents "GEODIST(latField, lonField, lat, lon, {options})".
+func (c *Cond) GeoDist(latField, lonField string, lat, lon float64, opts GeoDistOptions) string {
+	buf := &strings.Builder{}
+	buf.WriteString("GEODIST(")
+	buf.WriteString(Escape(latField))
+	buf.WriteString(", ")
+	buf.WriteString(Escape(lonField))
+	buf.WriteString(", ")
+	buf.WriteString(c.Args.Add(lat))
+	buf.WriteString(", ")
+	buf.WriteString(c.Args.Add(lon))
+
+	if s := opts.String(); s != "" {
+		buf.WriteString(", ")
+		buf.WriteString(s)
+	}
+
+	buf.WriteString(")")
+	return buf.String()
+}
+
+// Poly2D represents "POLY2D(x1, y1, x2, y2, ...)".
+func (c *Cond) Poly2D(points ...Point) string {
+	vs := make([]string, 0, len(points)*2)
+
+	for _, p := range points {
+		vs = append(vs, c.Args.Add(p.X), c.Args.Add(p.Y))
+	}
+
+	return fmt.Sprintf("POLY2D(%s)", strings.Join(vs, ", "))
+}
+
+// GeoPoly2D represents "GEOPOLY2D(lat1, lon1, lat2, lon2, ...)".
+func (c *Cond) GeoPoly2D(points ...GeoPoint) string {
+	vs := make([]string, 0, len(points)*2)
+
+	for _, p := range points {
+		vs = append(vs, c.Args.Add(p.Lat), c.Args.Add(p.Lon))
+	}
+
+	return fmt.Sprintf("GEOPOLY2D(%s)", strings.Join(vs, ", "))
+}
+
+// Contains represents "CONTAINS(polygon, xField, yField)".
+// The polygon is usually built by `Cond#Poly2D` or `Cond#GeoPoly2D`.
+func (c *Cond) Contains(polygon, xField, yField string) string {
+	return fmt.Sprintf("CONTAINS(%s, %s, %s)", polygon, Escape(xField), Escape(yField))
+}
+
+// WithinRadius adds "GEODIST(...) AS alias" to the columns in SELECT
+// and "alias <= radius" to the expressions of WHERE.
+// The radius is measured in the opts.Out units.
+//
+// As Select replaces all columns, WithinRadius must be called after it.
+func (sb *SelectBuilder) WithinRadius(alias, latField, lonField string, lat, lon, radius float64, opts GeoDistOptions) *SelectBuilder {
+	sb.selectCols = append(sb.selectCols, sb.As(sb.GeoDist(latField, lonField, lat, lon, opts), Escape(alias)))
+	return sb.Where(sb.LessEqualThan(alias, radius))
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func ExampleSelectBuilder_WithinRadius() {
+	sb := NewSelectBuilder()
+	sb.Select("id", "title")
+	sb.From("vacancies")
+	sb.WithinRadius("dist", "lat_rad", "lon_rad", 55.7558, 37.6173, 10, GeoDistOptions{
+		In:     GeoDistDegrees,
+		Out:    GeoDistKilometers,
+		Method: GeoDistAdaptive,
+	})
+	sb.OrderBy(sb.Asc("dist"))
+
+	s, args := sb.Build()
+	fmt.Println(s)
+	fmt.Println(args)
+
+	// Output:
+	// SELECT id, title, GEODIST(lat_rad, lon_rad, ?, ?, {in=deg, out=km, method=adaptive}) AS dist FROM vacancies WHERE dist <= ? ORDER BY dist ASC
+	// [55.7558 37.6173 10]
+}
+
+func ExampleCond_Contains() {
+	sb := NewSelectBuilder()
+	sb.Select("id")
+	sb.From("vacancies")
+	sb.Where(
+		sb.Contains(sb.GeoPoly2D(
+			GeoPoint{55.7, 37.5},
+			GeoPoint{55.8, 37.5},
+			GeoPoint{55.8, 37.7},
+		), "lat", "lon"),
+	)
+
+	s, args := sb.Build()
+	fmt.Println(s)
+	fmt.Println(args)
+
+	// Output:
+	// SELECT id FROM vacancies WHERE CONTAINS(GEOPOLY2D(?, ?, ?, ?, ?, ?), lat, lon)
+	// [55.7 37.5 55.8 37.5 55.8 37.7]
+}
+
+func TestGeo(t *testing.T) {
+	a := assert.New(t)
+	cases := map[string]func() string{
+		"GEODIST($$lat, $$lon, $0, $1)": func() string {
+			return newTestCond().GeoDist("$lat", "$lon", 0.1, 0.2, GeoDistOptions{})
+		},
+		"GEODIST(lat, lon, $0, $1, {out=mi})": func() string {
+			return newTestCond().GeoDist("lat", "lon", 0.1, 0.2, GeoDistOptions{Out: GeoDistMiles})
+		},
+		"GEODIST(lat, lon, $0, $1, {in=rad, method=haversine})": func() string {
+			return newTestCond().GeoDist("lat", "lon", 0.1, 0.2, GeoDistOptions{In: GeoDistRadians, Method: GeoDistHaversine})
+		},
+		"POLY2D($0, $1, $2, $3, $4, $5)": func() string {
+			return newTestCond().Poly2D(Point{1, 2}, Point{3, 4}, Point{5, 6})
+		},
+		"GEOPOLY2D($0, $1, $2, $3, $4, $5)": func() string {
+			return newTestCond().GeoPoly2D(GeoPoint{1, 2}, GeoPoint{3, 4}, GeoPoint{5, 6})
+		},
+		"CONTAINS(POLY2D($0, $1, $2, $3, $4, $5), $$x, y)": func() string {
+			c := newTestCond()
+			return c.Contains(c.Poly2D(Point{1, 2}, Point{3, 4}, Point{5, 6}), "$x", "y")
+		},
+	}
+
+	for expected, f := range cases {
+		actual := f()
+		a.Equal(actual, expected)
+	}
+}
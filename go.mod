@@ -1,8 +1,9 @@
 module github.com/superjobru/go-sphinxql
 
-go 1.17
+go 1.18
 
 require (
+	github.com/go-sql-driver/mysql v1.7.1
 	github.com/huandu/go-assert v1.1.5
 	github.com/huandu/xstrings v1.3.2
 )
@@ -0,0 +1,272 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"strings"
+	"sync"
+)
+
+// RedactedValue replaces sensitive args in `Query#RedactedArgs`.
+const RedactedValue = "<redacted>"
+
+// QueryKind is the kind of builder which builds a Query.
+type QueryKind int
+
+// QueryKind enum
+const (
+	// OtherQuery is built by `Build`, `Buildf`, `BuildNamed` or a custom builder.
+	OtherQuery QueryKind = iota
+	SelectQuery
+	InsertQuery
+	UpdateQuery
+	DeleteQuery
+)
+
+// String returns the name of k.
+func (k QueryKind) String() string {
+	switch k {
+	case SelectQuery:
+		return "SELECT"
+	case InsertQuery:
+		return "INSERT"
+	case UpdateQuery:
+		return "UPDATE"
+	case DeleteQuery:
+		return "DELETE"
+	}
+
+	return "OTHER"
+}
+
+// Query is a built query passed to hooks.
+// Hooks can modify SQL and Args, which are returned by `Build` and `BuildWithFlavor` then.
+type Query struct {
+	Kind    QueryKind
+	Builder Builder
+	Flavor  Flavor
+	SQL     string
+	Args    []interface{}
+
+	opt *Opt
+}
+
+// Hook is called with every query built by a top-level builder.
+// Nested builders, e.g. a sub-query in IN, don't trigger hooks.
+//
+// Hooks are called synchronously in the goroutine calling `Build`,
+// so they can start tracing spans, collect metrics or log queries.
+// A hook must not build q.Builder again with `Build`, as it would call hooks recursively.
+// Use `BuildWithoutHooks` or `Fingerprint` instead.
+type Hook func(q *Query)
+
+var (
+	hooksMu     sync.RWMutex
+	globalHooks []Hook
+)
+
+// AddHook appends hooks to the global chain, which is called for queries of all flavors.
+// Global hooks are called before hooks added by `Flavor#AddHook`.
+//
+// AddHook is expected to be called once during initialization.
+func AddHook(hook ...Hook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+
+	// Always allocate a new slice, so that a running chain is not affected.
+	globalHooks = append(globalHooks[:len(globalHooks):len(globalHooks)], hook...)
+}
+
+// ResetHooks removes all global hooks.
+// Hooks added by `Flavor#AddHook` are kept.
+func ResetHooks() {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+
+	globalHooks = nil
+}
+
+// AddHook appends hooks to the chain of flavor f.
+// They are called only for queries built with flavor f after global hooks.
+//
+// AddHook is expected to be called once during initialization.
+func (f Flavor) AddHook(hook ...Hook) {
+	f.updateSettings(func(fs *flavorSettings) {
+		fs.hooks = append(fs.hooks[:len(fs.hooks):len(fs.hooks)], hook...)
+	})
+}
+
+// ResetHooks removes all hooks of flavor f.
+func (f Flavor) ResetHooks() {
+	f.updateSettings(func(fs *flavorSettings) {
+		fs.hooks = nil
+	})
+}
+
+// runHooks passes a query built by b to global hooks and hooks of flavor.
+// It returns the query modified by hooks.
+func runHooks(b Builder, flavor Flavor, sql string, args []interface{}) (string, []interface{}) {
+	hooksMu.RLock()
+	hooks := globalHooks
+	hooksMu.RUnlock()
+
+	flavorHooks := flavor.settings().hooks
+
+	if len(hooks) == 0 && len(flavorHooks) == 0 {
+		return sql, args
+	}
+
+	q := &Query{
+		Kind:    queryKindOf(b),
+		Builder: b,
+		Flavor:  flavor,
+		SQL:     sql,
+		Args:    args,
+	}
+
+	for _, hook := range hooks {
+		hook(q)
+	}
+
+	for _, hook := range flavorHooks {
+		hook(q)
+	}
+
+	return q.SQL, q.Args
+}
+
+func queryKindOf(b Builder) QueryKind {
+	switch b.(type) {
+	case *SelectBuilder:
+		return SelectQuery
+	case *InsertBuilder:
+		return InsertQuery
+	case *UpdateBuilder:
+		return UpdateQuery
+	case *DeleteBuilder:
+		return DeleteQuery
+	}
+
+	return OtherQuery
+}
+
+// nestedBuilder is a builder which can be built without hooks when it's nested in another builder.
+type nestedBuilder interface {
+	build(flavor Flavor, initialArg ...interface{}) (sql string, args []interface{})
+
+	// defaultFlavor returns the flavor used by `Builder#Build`.
+	defaultFlavor() Flavor
+}
+
+// BuildWithoutHooks builds b like `Builder#Build` without calling hooks.
+// It's designed for tools inspecting queries, e.g. linters, which must not trigger
+// tracing or logging hooks. A custom builder is built by its `Build`.
+func BuildWithoutHooks(b Builder) (sql string, args []interface{}) {
+	if nb, ok := b.(nestedBuilder); ok {
+		return nb.build(nb.defaultFlavor())
+	}
+
+	return b.Build()
+}
+
+// buildNested builds b as a part of another query without calling hooks if possible.
+func buildNested(b Builder, flavor Flavor, initialArg ...interface{}) (sql string, args []interface{}) {
+	if nb, ok := b.(nestedBuilder); ok {
+		return nb.build(flavor, initialArg...)
+	}
+
+	return b.BuildWithFlavor(flavor, initialArg...)
+}
+
+// RedactedArgs returns a copy of q.Args with args marked by `Sensitive` replaced by RedactedValue.
+// It's designed to log a query with `Flavor#Interpolate`.
+func (q *Query) RedactedArgs() []interface{} {
+	if q.Args == nil {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(q.Args))
+
+	for _, arg := range q.Args {
+		if _, ok := arg.(sensitiveArgs); ok {
+			arg = RedactedValue
+		}
+
+		args = append(args, arg)
+	}
+
+	return args
+}
+
+// String returns the query interpolated with redacted args. It's designed for logging.
+// If the args cannot be interpolated, q.SQL is returned.
+func (q *Query) String() string {
+	if s, err := q.Flavor.Interpolate(q.SQL, q.RedactedArgs()); err == nil {
+		return s
+	}
+
+	return q.SQL
+}
+
+// Opt returns an Opt to build expressions for `Query#AddOption`.
+func (q *Query) Opt() *Opt {
+	if q.opt == nil {
+		q.opt = &Opt{
+			Args: &Args{
+				Flavor: q.Flavor,
+			},
+		}
+	}
+
+	return q.opt
+}
+
+// AddOption adds OPTION expressions built by `Query#Opt` to q.
+// If q has an OPTION clause, the expressions are appended to it.
+// Otherwise, a new OPTION clause is added before FACET or at the end of q.
+//
+// It's designed for SELECT and UPDATE, which support OPTION in SphinxQL.
+func (q *Query) AddOption(expr ...string) {
+	if len(expr) == 0 {
+		return
+	}
+
+	sql, args := q.Opt().Args.CompileWithFlavor(strings.Join(expr, ", "), q.Flavor)
+	pos := indexKeyword(q.SQL, "FACET")
+
+	if pos < 0 {
+		pos = len(q.SQL)
+	} else {
+		pos = len(strings.TrimRight(q.SQL[:pos], " "))
+	}
+
+	if indexKeyword(q.SQL[:pos], "OPTION") >= 0 {
+		sql = ", " + sql
+	} else {
+		sql = " OPTION " + sql
+	}
+
+	// Args of the expressions must be placed after args of placeholders before pos.
+	n := 0
+	scanQuery(q.SQL[:pos], func(kind byte, start, end int) error {
+		if kind == '?' {
+			n++
+		}
+
+		return nil
+	})
+
+	if n > len(q.Args) {
+		n = len(q.Args)
+	}
+
+	merged := make([]interface{}, 0, len(q.Args)+len(args))
+	merged = append(merged, q.Args[:n]...)
+	merged = append(merged, args...)
+	merged = append(merged, q.Args[n:]...)
+
+	q.SQL = q.SQL[:pos] + sql + q.SQL[pos:]
+	q.Args = merged
+}
@@ -0,0 +1,172 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func ExampleAddHook() {
+	AddHook(func(q *Query) {
+		q.AddOption(q.Opt().Comment("trace_id=42"))
+		fmt.Printf("%v: %v\n", q.Kind, q)
+	})
+	defer ResetHooks()
+
+	sb := NewSelectBuilder()
+	sb.Select("id").From("users")
+	sb.Where(sb.Equal("password", Sensitive("secret")))
+
+	s, args := sb.Build()
+	fmt.Println(s)
+	fmt.Println(args)
+
+	// Output:
+	// SELECT: SELECT id FROM users WHERE password = '<redacted>' OPTION comment = 'trace_id=42'
+	// SELECT id FROM users WHERE password = ? OPTION comment = ?
+	// [{secret} trace_id=42]
+}
+
+func TestHookChain(t *testing.T) {
+	a := assert.New(t)
+	var calls []string
+
+	AddHook(func(q *Query) { calls = append(calls, "global "+q.Kind.String()) })
+	SphinxSearch.AddHook(func(q *Query) { calls = append(calls, "flavor "+q.Kind.String()) })
+	t.Cleanup(func() {
+		ResetHooks()
+		SphinxSearch.ResetHooks()
+	})
+
+	sub := NewSelectBuilder()
+	sub.Select("id").From("t2")
+	sb := NewSelectBuilder()
+	sb.Select("id").From(sb.BuilderAs(sub, "t"))
+	sb.Build()
+	a.Equal(calls, []string{"global SELECT", "flavor SELECT"})
+
+	calls = nil
+	_ = sb.String()
+	a.Equal(len(calls), 0)
+
+	calls = nil
+	Build("SELECT $0", sub).Build()
+	NewInsertBuilder().InsertInto("t").Values(1).Build()
+	NewUpdateBuilder().Update("t").Set("a = 1").Build()
+	NewDeleteBuilder().DeleteFrom("t").Build()
+	a.Equal(calls, []string{
+		"global OTHER", "flavor OTHER",
+		"global INSERT", "flavor INSERT",
+		"global UPDATE", "flavor UPDATE",
+		"global DELETE", "flavor DELETE",
+	})
+}
+
+func TestHookModifyQuery(t *testing.T) {
+	a := assert.New(t)
+	AddHook(func(q *Query) {
+		q.SQL = "/* hooked */ " + q.SQL
+	})
+	t.Cleanup(ResetHooks)
+
+	s, args := Build("SELECT $0", 1).Build()
+	a.Equal(s, "/* hooked */ SELECT ?")
+	a.Equal(args, []interface{}{1})
+}
+
+func TestBuildWithoutHooks(t *testing.T) {
+	a := assert.New(t)
+	AddHook(func(q *Query) {
+		q.SQL = "/* hooked */ " + q.SQL
+	})
+	t.Cleanup(ResetHooks)
+
+	sb := NewSelectBuilder()
+	sb.Select("id").From("t").Where(sb.Equal("a", 1))
+	ib := NewInsertBuilder()
+	ib.InsertInto("t").Values(1)
+	ub := NewUpdateBuilder()
+	ub.Update("t").Set("a = 1")
+	db := NewDeleteBuilder()
+	db.DeleteFrom("t")
+
+	cases := map[Builder]string{
+		sb:                           "SELECT id FROM t WHERE a = ?",
+		ib:                           "INSERT INTO t VALUES (?)",
+		ub:                           "UPDATE t SET a = 1",
+		db:                           "DELETE FROM t",
+		Build("SELECT $0", 1):        "SELECT ?",
+		WithFlavor(sb, SphinxSearch): "SELECT id FROM t WHERE a = ?",
+	}
+
+	for b, expected := range cases {
+		s, _ := BuildWithoutHooks(b)
+		a.Equal(s, expected)
+
+		s, _ = b.Build()
+		a.Equal(s, "/* hooked */ "+expected)
+	}
+}
+
+func TestQueryAddOption(t *testing.T) {
+	a := assert.New(t)
+	cases := []struct {
+		sql          string
+		args         []interface{}
+		expectedSQL  string
+		expectedArgs []interface{}
+	}{
+		{
+			"SELECT * FROM t WHERE a = ?", []interface{}{1},
+			"SELECT * FROM t WHERE a = ? OPTION comment = ?", []interface{}{1, "x"},
+		},
+		{
+			"SELECT * FROM t WHERE a = ? OPTION ranker = ?", []interface{}{1, "bm25"},
+			"SELECT * FROM t WHERE a = ? OPTION ranker = ?, comment = ?", []interface{}{1, "bm25", "x"},
+		},
+		{
+			"SELECT * FROM t WHERE a = ? FACET b ORDER BY FACET() LIMIT ?", []interface{}{1, 10},
+			"SELECT * FROM t WHERE a = ? OPTION comment = ? FACET b ORDER BY FACET() LIMIT ?", []interface{}{1, "x", 10},
+		},
+		{
+			"SELECT * FROM t WHERE MATCH('option facet') OPTION max_matches = 10 FACET b", nil,
+			"SELECT * FROM t WHERE MATCH('option facet') OPTION max_matches = 10, comment = ? FACET b", []interface{}{"x"},
+		},
+	}
+
+	for _, c := range cases {
+		q := &Query{
+			Flavor: SphinxSearch,
+			SQL:    c.sql,
+			Args:   c.args,
+		}
+		q.AddOption(q.Opt().Comment("x"))
+		a.Equal(q.SQL, c.expectedSQL)
+		a.Equal(q.Args, c.expectedArgs)
+	}
+}
+
+func TestSensitive(t *testing.T) {
+	a := assert.New(t)
+	sb := NewSelectBuilder()
+	sb.Select("id").From("users").Where(sb.Equal("token", Sensitive("abc")), sb.Equal("id", 1))
+
+	s, args := sb.Build()
+	q := &Query{Flavor: SphinxSearch, SQL: s, Args: args}
+	a.Equal(q.RedactedArgs(), []interface{}{RedactedValue, 1})
+	a.Equal(q.String(), "SELECT id FROM users WHERE token = '<redacted>' AND id = 1")
+
+	interpolated, err := SphinxSearch.Interpolate(s, args)
+	a.NilError(err)
+	a.Equal(interpolated, "SELECT id FROM users WHERE token = 'abc' AND id = 1")
+
+	v, err := args[0].(driver.Valuer).Value()
+	a.NilError(err)
+	a.Equal(v, "abc")
+}
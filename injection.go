@@ -49,3 +49,14 @@ func (injection *injection) WriteTo(buf *strings.Builder, marker injectionMarker
 		buf.WriteRune(' ')
 	}
 }
+
+// clone returns a deep copy of injection.
+func (injection *injection) clone() *injection {
+	c := newInjection()
+
+	for marker, sqls := range injection.markerSQLs {
+		c.markerSQLs[marker] = append([]string(nil), sqls...)
+	}
+
+	return c
+}
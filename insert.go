@@ -107,10 +107,25 @@ func (ib *InsertBuilder) Values(value ...interface{}) *InsertBuilder {
 
 // String returns the compiled INSERT string.
 func (ib *InsertBuilder) String() string {
-	s, _ := ib.Build()
+	s, _ := ib.build(ib.args.Flavor)
 	return s
 }
 
+// StringInterpolated returns the compiled INSERT string with args interpolated.
+// Like String, it doesn't call hooks. It's designed for logging and debugging;
+// use BuildInterpolated to send a query, as the error of interpolation is returned in place of the query.
+func (ib *InsertBuilder) StringInterpolated() string {
+	sql, args := ib.build(ib.args.Flavor)
+	return interpolatedString(ib.args.Flavor, sql, args)
+}
+
+// BuildInterpolated returns compiled INSERT string with args interpolated by the flavor of ib.
+// It's designed for searchd, which doesn't support server-side prepared statements.
+func (ib *InsertBuilder) BuildInterpolated() (string, error) {
+	sql, args := ib.Build()
+	return ib.args.Flavor.Interpolate(sql, args)
+}
+
 // Build returns compiled INSERT string and args.
 // They can be used in `DB#Query` of package `database/sql` directly.
 func (ib *InsertBuilder) Build() (sql string, args []interface{}) {
@@ -120,6 +135,12 @@ func (ib *InsertBuilder) Build() (sql string, args []interface{}) {
 // BuildWithFlavor returns compiled INSERT string and args with flavor and initial args.
 // They can be used in `DB#Query` of package `database/sql` directly.
 func (ib *InsertBuilder) BuildWithFlavor(flavor Flavor, initialArg ...interface{}) (sql string, args []interface{}) {
+	sql, args = ib.build(flavor, initialArg...)
+	return runHooks(ib, flavor, sql, args)
+}
+
+// build builds ib without calling hooks.
+func (ib *InsertBuilder) build(flavor Flavor, initialArg ...interface{}) (sql string, args []interface{}) {
 	buf := &strings.Builder{}
 	ib.injection.WriteTo(buf, insertMarkerInit)
 	buf.WriteString(ib.verb)
@@ -148,6 +169,10 @@ func (ib *InsertBuilder) BuildWithFlavor(flavor Flavor, initialArg ...interface{
 	return ib.args.CompileWithFlavor(buf.String(), flavor, initialArg...)
 }
 
+func (ib *InsertBuilder) defaultFlavor() Flavor {
+	return ib.args.Flavor
+}
+
 // SetFlavor sets the flavor of compiled sql.
 func (ib *InsertBuilder) SetFlavor(flavor Flavor) (old Flavor) {
 	old = ib.args.Flavor
@@ -155,6 +180,31 @@ func (ib *InsertBuilder) SetFlavor(flavor Flavor) (old Flavor) {
 	return
 }
 
+// Clone returns a deep copy of ib.
+// Args, injected SQLs and nested builders are copied, so ib and the copy
+// can be modified and built independently, e.g. in different goroutines.
+func (ib *InsertBuilder) Clone() *InsertBuilder {
+	values := make([][]string, 0, len(ib.values))
+
+	for _, v := range ib.values {
+		values = append(values, cloneStrings(v))
+	}
+
+	return &InsertBuilder{
+		verb:      ib.verb,
+		table:     ib.table,
+		cols:      cloneStrings(ib.cols),
+		values:    values,
+		args:      ib.args.clone(),
+		injection: ib.injection.clone(),
+		marker:    ib.marker,
+	}
+}
+
+func (ib *InsertBuilder) cloneBuilder() Builder {
+	return ib.Clone()
+}
+
 // Var returns a placeholder for value.
 func (ib *InsertBuilder) Var(arg interface{}) string {
 	return ib.args.Add(arg)
@@ -6,6 +6,9 @@ package sphinxql
 
 import (
 	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
 )
 
 func ExampleInsertInto() {
@@ -116,3 +119,23 @@ func ExampleInsertBuilder_SQL() {
 	// /* before */ INSERT INTO demo.user PARTITION (p0) (id, name, status, created_at) /* after cols */ VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE status = ?
 	// [3 Shawn Du 1 1234567890 1]
 }
+
+func TestInsertBuilderClone(t *testing.T) {
+	a := assert.New(t)
+	base := NewInsertBuilder()
+	base.ReplaceInto("vacancies").Cols("id", "title")
+	base.Values(1, "golang")
+	base.SQL("/* base */")
+
+	c := base.Clone()
+	c.Values(2, "rust")
+	c.SQL("/* clone */")
+
+	s, args := base.Build()
+	a.Equal(s, "REPLACE INTO vacancies (id, title) VALUES (?, ?) /* base */")
+	a.Equal(args, []interface{}{1, "golang"})
+
+	s, args = c.Build()
+	a.Equal(s, "REPLACE INTO vacancies (id, title) VALUES (?, ?), (?, ?) /* base */ /* clone */")
+	a.Equal(args, []interface{}{1, "golang", 2, "rust"})
+}
@@ -5,16 +5,54 @@
 package sphinxql
 
 import (
+	"database/sql/driver"
 	"fmt"
+	"reflect"
 	"strconv"
+	"sync"
 	"time"
-	"unicode/utf8"
 	"unsafe"
 )
 
 // UnquotedString is a string which will be passed as-is during interpolation.
 type UnquotedString string
 
+// Encoder converts a value of a custom type to a value which can be interpolated,
+// e.g. a string, a number or an UnquotedString.
+type Encoder func(value interface{}, flavor Flavor) (interface{}, error)
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[reflect.Type]Encoder{}
+)
+
+// RegisterEncoder registers an encoder for the type of sample.
+// The encoder takes precedence over built-in rules of interpolation for values of this type.
+// If encoder is nil, the encoder registered for the type is removed.
+//
+// RegisterEncoder is expected to be called once during initialization.
+func RegisterEncoder(sample interface{}, encoder Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+
+	t := reflect.TypeOf(sample)
+
+	if encoder == nil {
+		delete(encoders, t)
+		return
+	}
+
+	encoders[t] = encoder
+}
+
+func lookupEncoder(t reflect.Type) (Encoder, bool) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	encoder, ok := encoders[t]
+	return encoder, ok
+}
+
 // sphinxSearchInterpolate parses query and replace all "?" with encoded args.
 // If there are more "?" than len(args), returns ErrMissingArgs.
 // Otherwise, if there are less "?" than len(args), the redundant args are omitted.
@@ -26,73 +64,73 @@ func sphinxSearchLikeInterpolate(flavor Flavor, query string, args ...interface{
 	// Roughly estimate the size to avoid useless memory allocation and copy.
 	buf := make([]byte, 0, len(query)+len(args)*20)
 
-	var quote rune
-	var err error
 	cnt := 0
-	max := len(args)
-	escaping := false
-	offset := 0
-	target := query
-	r, sz := utf8.DecodeRuneInString(target)
+	last := 0
+	err := scanQuery(query, func(kind byte, start, end int) (err error) {
+		if kind != '?' {
+			return
+		}
 
-	for ; sz != 0; r, sz = utf8.DecodeRuneInString(target) {
-		offset += sz
-		target = query[offset:]
+		if cnt >= len(args) {
+			return ErrInterpolateMissingArgs
+		}
+
+		buf = append(buf, query[last:start]...)
+		buf, err = encodeValue(buf, args[cnt], flavor)
+		last = end
+		cnt++
+		return
+	})
 
+	if err != nil {
+		return "", err
+	}
+
+	buf = append(buf, query[last:]...)
+	return *(*string)(unsafe.Pointer(&buf)), nil
+}
+
+// scanQuery walks through query and calls fn for every "?" placeholder and every quoted token.
+// The kind is '?' for a placeholder, or the quote character for a string or an identifier.
+// The start and end are byte offsets of the token including quotes.
+// An unterminated quoted token is not reported. Scanning stops at the first error returned by fn.
+func scanQuery(query string, fn func(kind byte, start, end int) error) error {
+	var quote byte
+	escaping := false
+	quoteStart := 0
+
+	// All interesting characters are ASCII, so it's safe to iterate over UTF-8 bytes.
+	for i := 0; i < len(query); i++ {
 		if escaping {
 			escaping = false
 			continue
 		}
 
-		switch r {
+		switch c := query[i]; c {
 		case '?':
 			if quote != 0 {
 				continue
 			}
 
-			if cnt >= max {
-				return "", ErrInterpolateMissingArgs
-			}
-
-			buf = append(buf, query[:offset-sz]...)
-			buf, err = encodeValue(buf, args[cnt], flavor)
-
-			if err != nil {
-				return "", err
-			}
-
-			query = target
-			offset = 0
-			cnt++
-
-		case '\'':
-			if quote == '\'' {
-				quote = 0
-				continue
+			if err := fn('?', i, i+1); err != nil {
+				return err
 			}
 
+		case '\'', '"', '`':
 			if quote == 0 {
-				quote = '\''
-			}
-
-		case '"':
-			if quote == '"' {
-				quote = 0
+				quote = c
+				quoteStart = i
 				continue
 			}
 
-			if quote == 0 {
-				quote = '"'
-			}
-
-		case '`':
-			if quote == '`' {
-				quote = 0
+			if quote != c {
 				continue
 			}
 
-			if quote == 0 {
-				quote = '`'
+			quote = 0
+
+			if err := fn(c, quoteStart, i+1); err != nil {
+				return err
 			}
 
 		case '\\':
@@ -102,11 +140,25 @@ func sphinxSearchLikeInterpolate(flavor Flavor, query string, args ...interface{
 		}
 	}
 
-	buf = append(buf, query...)
-	return *(*string)(unsafe.Pointer(&buf)), nil
+	return nil
 }
 
 func encodeValue(buf []byte, arg interface{}, flavor Flavor) ([]byte, error) {
+	if encoder, ok := lookupEncoder(reflect.TypeOf(arg)); ok {
+		v, err := encoder(arg, flavor)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Encode a value of the same type with built-in rules to avoid infinite recursion.
+		if reflect.TypeOf(v) != reflect.TypeOf(arg) {
+			return encodeValue(buf, v, flavor)
+		}
+
+		arg = v
+	}
+
 	switch v := arg.(type) {
 	case nil:
 		buf = append(buf, "NULL"...)
@@ -149,10 +201,16 @@ func encodeValue(buf []byte, arg interface{}, flavor Flavor) ([]byte, error) {
 		buf = strconv.AppendUint(buf, v, 10)
 
 	case float32:
-		buf = strconv.AppendFloat(buf, float64(v), 'g', -1, 32)
+		return appendFloat(buf, float64(v), 32, flavor.FloatPolicy())
 
 	case float64:
-		buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+		return appendFloat(buf, v, 64, flavor.FloatPolicy())
+
+	case FloatVector:
+		return appendFloatVector(buf, v, flavor.FloatPolicy())
+
+	case []float32:
+		return appendFloatVector(buf, v, flavor.FloatPolicy())
 
 	case []byte:
 		if v == nil {
@@ -170,36 +228,117 @@ func encodeValue(buf []byte, arg interface{}, flavor Flavor) ([]byte, error) {
 		buf = append(buf, string(v)...)
 
 	case time.Time:
-		if v.IsZero() {
-			buf = append(buf, "'0000-00-00'"...)
+		buf = appendTime(buf, v, flavor.TimeEncoding())
+
+	case timeArgs:
+		buf = appendTime(buf, v.t, v.enc)
+
+	case sensitiveArgs:
+		return encodeValue(buf, v.arg, flavor)
+
+	case matchTextArgs:
+		buf = quoteStringValue(buf, EscapeMatch(v.text), flavor)
+
+	case driver.Valuer:
+		// Follow database/sql, which treats a nil pointer to a Valuer as NULL.
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			buf = append(buf, "NULL"...)
 			break
 		}
 
-		// In SQL standard, the precision of fractional seconds in time literal is up to 6 digits.
-		// Round up v.
-		v = v.Add(500 * time.Nanosecond)
-		buf = append(buf, '\'')
+		value, err := v.Value()
 
-		buf = append(buf, v.Format("2006-01-02 15:04:05.999999")...)
-
-		buf = append(buf, '\'')
+		if err != nil {
+			return nil, err
+		}
 
-	case fmt.Stringer:
-		buf = quoteStringValue(buf, v.String(), flavor)
+		return encodeValue(buf, value, flavor)
 
 	default:
-		return nil, ErrInterpolateUnsupportedArgs
+		return encodeReflectValue(buf, arg, flavor)
 	}
 
 	return buf, nil
 }
 
-func quoteStringValue(buf []byte, s string, _ Flavor) []byte {
+// encodeReflectValue encodes arg according to its kind.
+// Pointers are dereferenced and types like `type Status int` are encoded as their underlying types.
+// A `fmt.Stringer` which is not a number, a bool or a string is encoded as a string.
+func encodeReflectValue(buf []byte, arg interface{}, flavor Flavor) ([]byte, error) {
+	rv := reflect.ValueOf(arg)
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return append(buf, "NULL"...), nil
+		}
+
+		encoded, err := encodeValue(buf, rv.Elem().Interface(), flavor)
+
+		if err != ErrInterpolateUnsupportedArgs {
+			return encoded, err
+		}
+
+	case reflect.Bool:
+		return encodeValue(buf, rv.Bool(), flavor)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return encodeValue(buf, rv.Int(), flavor)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return encodeValue(buf, rv.Uint(), flavor)
+
+	case reflect.Float32:
+		return encodeValue(buf, float32(rv.Float()), flavor)
+
+	case reflect.Float64:
+		return encodeValue(buf, rv.Float(), flavor)
+
+	case reflect.String:
+		return encodeValue(buf, rv.String(), flavor)
+	}
+
+	if s, ok := arg.(fmt.Stringer); ok {
+		return quoteStringValue(buf, s.String(), flavor), nil
+	}
+
+	return nil, ErrInterpolateUnsupportedArgs
+}
+
+// interpolatedString returns sql interpolated with args by flavor.
+// If args cannot be interpolated, the error is returned in place of the query,
+// so that the string with placeholders cannot be sent to searchd by mistake.
+func interpolatedString(flavor Flavor, sql string, args []interface{}) string {
+	s, err := flavor.Interpolate(sql, args)
+
+	if err != nil {
+		return fmt.Sprintf("[ERROR: %v] %s", err, sql)
+	}
+
+	return s
+}
+
+// quoteStringValue appends s quoted as a string literal of flavor to buf.
+// SphinxSearch is the only SphinxQL dialect, so other flavors, e.g. custom flavors
+// created for their own settings, quote strings in the same way.
+// A dialect with other escaping rules gets its own case here.
+func quoteStringValue(buf []byte, s string, flavor Flavor) []byte {
+	switch flavor {
+	case SphinxSearch:
+		return quoteSphinxString(buf, s)
+	}
+
+	return quoteSphinxString(buf, s)
+}
+
+// quoteSphinxString quotes s according to the unescaping rules of searchd.
+// Unlike MySQL, searchd doesn't know "\Z" and reads a quoted string up to an unescaped "'" only,
+// so "\x1a" and `"` are kept as is. A "\0" is read as a space by searchd.
+func quoteSphinxString(buf []byte, s string) []byte {
 	buf = append(buf, '\'')
-	r, sz := utf8.DecodeRuneInString(s)
 
-	for ; sz != 0; r, sz = utf8.DecodeRuneInString(s) {
-		switch r {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
 		case '\x00':
 			buf = append(buf, "\\0"...)
 
@@ -215,23 +354,12 @@ func quoteStringValue(buf []byte, s string, _ Flavor) []byte {
 		case '\t':
 			buf = append(buf, "\\t"...)
 
-		case '\x1a':
-			buf = append(buf, "\\Z"...)
-
-		case '\'':
-			buf = append(buf, "\\'"...)
-
-		case '"':
-			buf = append(buf, "\\\""...)
-
-		case '\\':
-			buf = append(buf, "\\\\"...)
+		case '\'', '\\':
+			buf = append(buf, '\\', c)
 
 		default:
-			buf = append(buf, s[:sz]...)
+			buf = append(buf, c)
 		}
-
-		s = s[sz:]
 	}
 
 	buf = append(buf, '\'')
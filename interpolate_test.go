@@ -5,6 +5,11 @@
 package sphinxql
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -29,17 +34,22 @@ func TestFlavorInterpolate(t *testing.T) {
 		{
 			SphinxSearch,
 			"SELECT * FROM `a?` WHERE name = \"?\" AND state IN (?, '?', ?, ?, ?, ?, ?)", []interface{}{"\r\n\b\t\x1a\x00\\\"'", uint(42), uint8(8), uint16(16), uint32(32), uint64(64), "useless"},
-			"SELECT * FROM `a?` WHERE name = \"?\" AND state IN ('\\r\\n\\b\\t\\Z\\0\\\\\\\"\\'', '?', 42, 8, 16, 32, 64)", nil,
+			"SELECT * FROM `a?` WHERE name = \"?\" AND state IN ('\\r\\n\\b\\t\x1a\\0\\\\\"\\'', '?', 42, 8, 16, 32, 64)", nil,
 		},
 		{
 			SphinxSearch,
 			"SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?", []interface{}{true, false, float32(1.234567), 9.87654321, []byte(nil), []byte("I'm bytes"), dt, time.Time{}, nil},
-			"SELECT TRUE, FALSE, 1.234567, 9.87654321, NULL, _binary'I\\'m bytes', '2019-04-24 12:23:34.123457', '0000-00-00', NULL", nil,
+			"SELECT TRUE, FALSE, 1.234567, 9.87654321, NULL, _binary'I\\'m bytes', 1556079814, 0, NULL", nil,
+		},
+		{
+			SphinxSearch,
+			"SELECT ?, ?, ?", []interface{}{FloatVector{0.1, -2, 3e-5}, []float32{}, FloatVector(nil)},
+			"SELECT (0.1, -2, 0.00003), (), ()", nil,
 		},
 		{
 			SphinxSearch,
 			"SELECT '\\'?', \"\\\"?\", `\\`?`, \\?", []interface{}{SphinxSearch},
-			"SELECT '\\'?', \"\\\"?\", `\\`?`, \\'SphinxSearch'", nil,
+			"SELECT '\\'?', \"\\\"?\", `\\`?`, \\1", nil,
 		},
 		{
 			SphinxSearch,
@@ -61,3 +71,127 @@ func TestFlavorInterpolate(t *testing.T) {
 		a.Assert(err == c.err || err.Error() == c.err.Error())
 	}
 }
+
+type testStringerInt int
+
+func (s testStringerInt) String() string {
+	return fmt.Sprintf("status-%d", int(s))
+}
+
+type testName string
+
+type testPoint struct {
+	X, Y int
+}
+
+func (p *testPoint) String() string {
+	return fmt.Sprintf("%d:%d", p.X, p.Y)
+}
+
+type testFailingValuer struct{}
+
+var errTestValuer = errors.New("valuer error")
+
+func (testFailingValuer) Value() (driver.Value, error) {
+	return nil, errTestValuer
+}
+
+func TestInterpolateValues(t *testing.T) {
+	a := assert.New(t)
+	i := 42
+	pi := &i
+	var nilInt *int
+	var nilNullString *sql.NullString
+	s := "x"
+	cases := []struct {
+		arg      interface{}
+		expected string
+		err      error
+	}{
+		{sql.NullString{String: "a'b", Valid: true}, "'a\\'b'", nil},
+		{sql.NullString{}, "NULL", nil},
+		{sql.NullInt64{Int64: 7, Valid: true}, "7", nil},
+		{sql.NullFloat64{Float64: 1.5, Valid: true}, "1.5", nil},
+		{sql.NullBool{Bool: true, Valid: true}, "TRUE", nil},
+		{&sql.NullInt64{Int64: 8, Valid: true}, "8", nil},
+		{nilNullString, "NULL", nil},
+		{&i, "42", nil},
+		{&pi, "42", nil},
+		{nilInt, "NULL", nil},
+		{&s, "'x'", nil},
+		{testStringerInt(3), "3", nil},
+		{testName("it's"), "'it\\'s'", nil},
+		{&testPoint{1, 2}, "'1:2'", nil},
+		{testPoint{1, 2}, "", ErrInterpolateUnsupportedArgs},
+		{testFailingValuer{}, "", errTestValuer},
+	}
+
+	for _, c := range cases {
+		a.Use(&c)
+		query, err := SphinxSearch.Interpolate("?", []interface{}{c.arg})
+		a.Equal(query, c.expected)
+		a.Equal(err, c.err)
+	}
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	a := assert.New(t)
+	RegisterEncoder(testPoint{}, func(value interface{}, flavor Flavor) (interface{}, error) {
+		p := value.(testPoint)
+		return fmt.Sprintf("(%d, %d)", p.X, p.Y), nil
+	})
+	RegisterEncoder(testName(""), func(value interface{}, flavor Flavor) (interface{}, error) {
+		return testName(strings.ToUpper(string(value.(testName)))), nil
+	})
+	t.Cleanup(func() {
+		RegisterEncoder(testPoint{}, nil)
+		RegisterEncoder(testName(""), nil)
+	})
+
+	query, err := SphinxSearch.Interpolate("SELECT ?, ?, ?", []interface{}{testPoint{1, 2}, &testPoint{3, 4}, testName("abc")})
+	a.NilError(err)
+	a.Equal(query, "SELECT '(1, 2)', '(3, 4)', 'ABC'")
+}
+
+func TestQuoteString(t *testing.T) {
+	a := assert.New(t)
+
+	// The searchd reads "\b", "\n", "\r", "\t" as control characters, "\0" as a space
+	// and any other escaped character as is. Only "'" and "\" must be escaped.
+	cases := map[string]string{
+		"":                   `''`,
+		"it's":               `'it\'s'`,
+		`C:\dir`:             `'C:\\dir'`,
+		`\'`:                 `'\\\''`,
+		"\x00\b\n\r\t":       `'\0\b\n\r\t'`,
+		"\x1a":               "'\x1a'",
+		`"quoted"`:           `'"quoted"'`,
+		`\Z \x`:              `'\\Z \\x'`,
+		"ü\xff":              "'ü\xff'",
+		`'; DROP TABLE t --`: `'\'; DROP TABLE t --'`,
+	}
+
+	for s, expected := range cases {
+		a.Use(&s)
+		a.Equal(SphinxSearch.QuoteString(s), expected)
+
+		// A custom flavor quotes strings like SphinxSearch.
+		a.Equal(Flavor(100).QuoteString(s), expected)
+	}
+}
+
+func TestInterpolateMatchText(t *testing.T) {
+	a := assert.New(t)
+	sb := NewSelectBuilder()
+	sb.Select("id").From("idx")
+	sb.Where(sb.MatchText(`C:\dir "x" -y`))
+
+	s, args := sb.Build()
+	query, err := SphinxSearch.Interpolate(s, args)
+	a.NilError(err)
+	a.Equal(query, `SELECT id FROM idx WHERE MATCH('C:\\\\dir \\"x\\" \\-y')`)
+
+	v, err := args[0].(driver.Valuer).Value()
+	a.NilError(err)
+	a.Equal(v, `C:\\dir \"x\" \-y`)
+}
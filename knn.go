@@ -0,0 +1,60 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrFloatVectorNotInterpolated means that a FloatVector is passed to a driver as a query arg.
+// SphinxQL has no placeholder for a vector, so the query must be interpolated client-side.
+var ErrFloatVectorNotInterpolated = errors.New("go-sphinxql: FloatVector must be interpolated by BuildInterpolated or NewConnector")
+
+// FloatVector is a value of a float_vector attribute.
+// It's interpolated as "(0.1, 0.2, ...)", so it can be used both in
+// `InsertBuilder#Values` and in KNN search.
+//
+// A vector can only be written in SphinxQL as a literal, so queries with a FloatVector
+// must be interpolated by `Flavor#Interpolate`, `BuildInterpolated` or a connection
+// created by `NewConnector`.
+type FloatVector []float32
+
+// Value implements `driver.Valuer`. It always fails with ErrFloatVectorNotInterpolated,
+// so that a query with a vector is not sent to a driver with placeholders by mistake.
+func (v FloatVector) Value() (driver.Value, error) {
+	return nil, ErrFloatVectorNotInterpolated
+}
+
+// KNN adds a "KNN(field, k, (vector...), ef)" expression to WHERE in SELECT.
+// The ef is omitted if it's not positive.
+//
+// The vector is added as a FloatVector, so the query must be interpolated,
+// e.g. by `SelectBuilder#BuildInterpolated`.
+func (sb *SelectBuilder) KNN(field string, k int, vector []float32, ef int) *SelectBuilder {
+	buf := &strings.Builder{}
+	buf.WriteString("KNN(")
+	buf.WriteString(Escape(field))
+	buf.WriteString(", ")
+	buf.WriteString(strconv.Itoa(k))
+	buf.WriteString(", ")
+	buf.WriteString(sb.args.Add(FloatVector(vector)))
+
+	if ef > 0 {
+		buf.WriteString(", ")
+		buf.WriteString(strconv.Itoa(ef))
+	}
+
+	buf.WriteString(")")
+	return sb.Where(buf.String())
+}
+
+// KNNDist returns a "KNN_DIST()" expression.
+// It can be used in SELECT and ORDER BY of a query with KNN search.
+func (sb *SelectBuilder) KNNDist() string {
+	return "KNN_DIST()"
+}
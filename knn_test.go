@@ -0,0 +1,65 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func ExampleSelectBuilder_KNN() {
+	sb := NewSelectBuilder()
+	sb.Select("id", sb.As(sb.KNNDist(), "dist"))
+	sb.From("vacancies")
+	sb.KNN("embedding", 10, []float32{0.1, 0.2, 0.3}, 2000)
+	sb.OrderBy(sb.Asc(sb.KNNDist()))
+
+	s, args := sb.Build()
+	query, err := SphinxSearch.Interpolate(s, args)
+	fmt.Println(s)
+	fmt.Println(query)
+	fmt.Println(err)
+
+	// Output:
+	// SELECT id, KNN_DIST() AS dist FROM vacancies WHERE KNN(embedding, 10, ?, 2000) ORDER BY KNN_DIST() ASC
+	// SELECT id, KNN_DIST() AS dist FROM vacancies WHERE KNN(embedding, 10, (0.1, 0.2, 0.3), 2000) ORDER BY KNN_DIST() ASC
+	// <nil>
+}
+
+func ExampleFloatVector() {
+	ib := NewInsertBuilder()
+	ib.InsertInto("vacancies")
+	ib.Cols("id", "embedding")
+	ib.Values(1, FloatVector{0.5, -0.25, 1})
+
+	s, args := ib.Build()
+	query, err := SphinxSearch.Interpolate(s, args)
+	fmt.Println(query)
+	fmt.Println(err)
+
+	// Output:
+	// INSERT INTO vacancies (id, embedding) VALUES (1, (0.5, -0.25, 1))
+	// <nil>
+}
+
+func TestKNNRequiresInterpolation(t *testing.T) {
+	a := assert.New(t)
+	sb := NewSelectBuilder()
+	sb.Select("id").From("vacancies")
+	sb.KNN("embedding", 10, []float32{0.1, 0.2}, 0)
+
+	// A driver converting args of Build must refuse the vector.
+	_, args := sb.Build()
+	_, err := driver.DefaultParameterConverter.ConvertValue(args[0])
+	a.Assert(errors.Is(err, ErrFloatVectorNotInterpolated))
+
+	query, err := sb.BuildInterpolated()
+	a.NilError(err)
+	a.Equal(query, "SELECT id FROM vacancies WHERE KNN(embedding, 10, (0.1, 0.2))")
+}
@@ -0,0 +1,500 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+// Package lint finds SphinxQL-specific mistakes in queries before they reach searchd.
+//
+// It works on builders of package sphinxql and on statements parsed by package parser.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	sphinxql "github.com/superjobru/go-sphinxql"
+	"github.com/superjobru/go-sphinxql/parser"
+)
+
+// Severity is the severity of an Issue.
+type Severity int
+
+// Severity enum.
+const (
+	// SeverityWarning means that the query works but likely not as expected.
+	SeverityWarning Severity = iota
+
+	// SeverityError means that searchd refuses the query or returns wrong results.
+	SeverityError
+)
+
+// String returns the name of s.
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+
+	return fmt.Sprintf("<invalid severity %d>", int(s))
+}
+
+// Code identifies the kind of an Issue.
+type Code string
+
+// Code enum.
+const (
+	CodeSyntax                 Code = "syntax"
+	CodeLimitBeyondMaxMatches  Code = "limit-beyond-max-matches"
+	CodeOrderByFullTextField   Code = "order-by-full-text-field"
+	CodeMultipleMatch          Code = "multiple-match"
+	CodeMatchInsideOr          Code = "match-inside-or"
+	CodeMatchOrFilter          Code = "match-or-filter"
+	CodeHavingWithoutGroupBy   Code = "having-without-group-by"
+	CodeRankerExprWithoutMatch Code = "ranker-expr-without-match"
+	CodeLikeOnNonString        Code = "like-on-non-string"
+)
+
+// Issue is a problem found in a query.
+type Issue struct {
+	Code     Code
+	Severity Severity
+
+	// Pos is the byte offset of the problem in the query.
+	// For a builder, it's the offset in the SQL returned by `Builder#Build`,
+	// or 0 if the problem is dropped from the SQL, e.g. HAVING without GROUP BY.
+	Pos int
+
+	Message string
+}
+
+// String returns the issue in the form of "error at 10: multiple-match: message".
+func (i Issue) String() string {
+	return fmt.Sprintf("%v at %d: %v: %s", i.Severity, i.Pos, i.Code, i.Message)
+}
+
+// AttrType is the type of an attribute in an index.
+type AttrType int
+
+// AttrType enum.
+const (
+	AttrUint AttrType = iota
+	AttrBigint
+	AttrFloat
+	AttrBool
+	AttrTimestamp
+	AttrString
+	AttrJSON
+	AttrMulti
+	AttrMulti64
+	AttrFloatVector
+)
+
+// Schema describes an index. It's optional, but some checks need it.
+type Schema struct {
+	// FullTextFields are fields which are indexed but not stored as attributes.
+	FullTextFields []string
+
+	// Attributes are types of attributes by names.
+	Attributes map[string]AttrType
+}
+
+// Linter checks queries. The zero value is ready to use.
+type Linter struct {
+	Schema Schema
+
+	// MaxMatches is the max_matches of searchd.
+	// Zero means `sphinxql.DefaultMaxMatches`.
+	MaxMatches int
+}
+
+// Lint checks the query built by b with a zero Linter.
+func Lint(b sphinxql.Builder) []Issue {
+	return (&Linter{}).Lint(b)
+}
+
+// LintStatement checks stmt with a zero Linter.
+// The params are values of placeholders "?" in stmt, which are used to evaluate OPTION values.
+func LintStatement(stmt parser.Statement, params ...interface{}) []Issue {
+	return (&Linter{}).LintStatement(stmt, params...)
+}
+
+// Lint builds b and checks the query. Hooks are not called.
+// Issues are sorted by positions in the SQL returned by `Builder#Build`.
+func (l *Linter) Lint(b sphinxql.Builder) []Issue {
+	sql, args := sphinxql.BuildWithoutHooks(b)
+	stmt, err := parser.Parse(sql)
+
+	if err != nil {
+		issue := Issue{
+			Code:     CodeSyntax,
+			Severity: SeverityError,
+			Message:  err.Error(),
+		}
+		var se *parser.SyntaxError
+
+		if errors.As(err, &se) {
+			issue.Pos = se.Pos
+		}
+
+		return []Issue{issue}
+	}
+
+	issues := l.LintStatement(stmt, args...)
+
+	// SelectBuilder drops HAVING without GROUP BY, so the SQL doesn't show the problem.
+	if sb, ok := b.(*sphinxql.SelectBuilder); ok && errors.Is(sb.Validate(), sphinxql.ErrHavingWithoutGroupBy) {
+		issues = append([]Issue{{
+			Code:     CodeHavingWithoutGroupBy,
+			Severity: SeverityError,
+			Message:  "HAVING requires GROUP BY; it's dropped from the query",
+		}}, issues...)
+	}
+
+	return issues
+}
+
+// LintStatement checks stmt. Issues are sorted by positions.
+// The params are values of placeholders "?" in stmt, which are used to evaluate OPTION values.
+func (l *Linter) LintStatement(stmt parser.Statement, params ...interface{}) []Issue {
+	c := &checker{
+		linter: l,
+		params: params,
+	}
+
+	switch s := stmt.(type) {
+	case *parser.SelectStmt:
+		c.selectStmt(s)
+	case *parser.UpdateStmt:
+		c.where(s.Where)
+	case *parser.DeleteStmt:
+		c.where(s.Where)
+	}
+
+	sort.SliceStable(c.issues, func(i, j int) bool {
+		return c.issues[i].Pos < c.issues[j].Pos
+	})
+
+	return c.issues
+}
+
+type checker struct {
+	linter *Linter
+	params []interface{}
+	issues []Issue
+}
+
+func (c *checker) report(code Code, severity Severity, pos int, format string, arg ...interface{}) {
+	c.issues = append(c.issues, Issue{
+		Code:     code,
+		Severity: severity,
+		Pos:      pos,
+		Message:  fmt.Sprintf(format, arg...),
+	})
+}
+
+func (c *checker) selectStmt(s *parser.SelectStmt) {
+	hasMatch := c.where(s.Where)
+
+	// The parser rejects such a query, but a statement can be modified after parsing.
+	if s.Having != nil && len(s.GroupBy) == 0 {
+		c.report(CodeHavingWithoutGroupBy, SeverityError, startPos(s.Having), "HAVING requires GROUP BY")
+	}
+
+	c.orderBy(s.WithinGroupOrderBy)
+	c.orderBy(s.OrderBy)
+	c.limit(s)
+
+	for _, o := range s.Options {
+		if !strings.EqualFold(o.Name, "ranker") || hasMatch {
+			continue
+		}
+
+		if f, ok := o.Value.(*parser.FuncCall); ok && strings.EqualFold(f.Name, "expr") {
+			c.report(CodeRankerExprWithoutMatch, SeverityWarning, o.Value.Pos(), "ranker=expr has no effect without MATCH")
+		}
+	}
+
+	c.like(s.Having)
+}
+
+// where checks MATCH in a condition of WHERE and returns true if there is a MATCH.
+func (c *checker) where(e parser.Expr) bool {
+	if e == nil {
+		return false
+	}
+
+	matches := findMatches(e)
+
+	for i, m := range matches {
+		if i > 0 {
+			c.report(CodeMultipleMatch, SeverityError, m.Pos(), "only one MATCH is allowed in a query")
+		}
+	}
+
+	if len(matches) > 0 {
+		c.or(e)
+	}
+
+	c.like(e)
+	return len(matches) > 0
+}
+
+// or checks MATCH used in OR.
+func (c *checker) or(e parser.Expr) {
+	if b, ok := e.(*parser.BinaryExpr); ok && isOr(b) {
+		operands := orOperands(b)
+		var withMatch []parser.Expr
+
+		for _, o := range operands {
+			if len(findMatches(o)) > 0 {
+				withMatch = append(withMatch, o)
+			}
+		}
+
+		switch {
+		case len(withMatch) == 0:
+			// Nothing.
+		case len(withMatch) < len(operands):
+			c.report(CodeMatchOrFilter, SeverityError, b.Pos(),
+				"OR cannot mix MATCH with attribute filters; move the alternatives into the full-text query or run separate queries")
+		default:
+			for _, o := range withMatch {
+				for _, m := range findMatches(o) {
+					c.report(CodeMatchInsideOr, SeverityError, m.Pos(),
+						"MATCH cannot be used inside OR; use the full-text operator | instead")
+				}
+			}
+		}
+
+		return
+	}
+
+	for _, child := range children(e) {
+		c.or(child)
+	}
+}
+
+func (c *checker) orderBy(items []parser.OrderItem) {
+	for _, item := range items {
+		ident, ok := item.Expr.(*parser.Ident)
+
+		if !ok || !c.isFullTextField(ident.Name) {
+			continue
+		}
+
+		c.report(CodeOrderByFullTextField, SeverityError, ident.Pos(), "cannot sort by full-text field %s, which is not an attribute", ident.Name)
+	}
+}
+
+func (c *checker) limit(s *parser.SelectStmt) {
+	if s.Limit < 0 {
+		return
+	}
+
+	maxMatches := c.linter.MaxMatches
+
+	if maxMatches <= 0 {
+		maxMatches = sphinxql.DefaultMaxMatches
+	}
+
+	for _, o := range s.Options {
+		if !strings.EqualFold(o.Name, "max_matches") {
+			continue
+		}
+
+		v, ok := c.intValue(o.Value)
+
+		if !ok {
+			return
+		}
+
+		maxMatches = v
+	}
+
+	offset := s.Offset
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	required := offset + s.Limit
+
+	if required <= maxMatches {
+		return
+	}
+
+	// The searchd truncates the result silently. Without offset, the first rows are still returned.
+	if offset == 0 {
+		c.report(CodeLimitBeyondMaxMatches, SeverityWarning, s.LimitPos,
+			"LIMIT %d requires max_matches of at least %d, but it's %d; only %d rows will be returned", s.Limit, required, maxMatches, maxMatches)
+		return
+	}
+
+	if offset >= maxMatches {
+		c.report(CodeLimitBeyondMaxMatches, SeverityError, s.LimitPos,
+			"LIMIT %d,%d requires max_matches of at least %d, but it's %d; no row will be returned", offset, s.Limit, required, maxMatches)
+		return
+	}
+
+	c.report(CodeLimitBeyondMaxMatches, SeverityError, s.LimitPos,
+		"LIMIT %d,%d requires max_matches of at least %d, but it's %d; only %d rows will be returned", offset, s.Limit, required, maxMatches, maxMatches-offset)
+}
+
+// like checks LIKE used on attributes.
+func (c *checker) like(e parser.Expr) {
+	if e == nil {
+		return
+	}
+
+	if b, ok := e.(*parser.BinaryExpr); ok && (b.Op == "LIKE" || b.Op == "NOT LIKE") {
+		if ident, ok := b.Left.(*parser.Ident); ok {
+			if t, ok := c.attrType(ident.Name); ok && t != AttrString {
+				c.report(CodeLikeOnNonString, SeverityError, b.Pos(), "%s can be used only on string attributes, but %s is not", b.Op, ident.Name)
+			}
+		}
+	}
+
+	for _, child := range children(e) {
+		c.like(child)
+	}
+}
+
+func (c *checker) isFullTextField(name string) bool {
+	for _, f := range c.linter.Schema.FullTextFields {
+		if strings.EqualFold(f, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *checker) attrType(name string) (AttrType, bool) {
+	if t, ok := c.linter.Schema.Attributes[name]; ok {
+		return t, true
+	}
+
+	for n, t := range c.linter.Schema.Attributes {
+		if strings.EqualFold(n, name) {
+			return t, true
+		}
+	}
+
+	return 0, false
+}
+
+// intValue evaluates a numeric literal or a placeholder.
+func (c *checker) intValue(e parser.Expr) (int, bool) {
+	var v interface{}
+
+	switch e := e.(type) {
+	case *parser.NumberLit:
+		v = e.Value
+	case *parser.Param:
+		if e.Index >= len(c.params) {
+			return 0, false
+		}
+
+		v = c.params[e.Index]
+	default:
+		return 0, false
+	}
+
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case uint:
+		return int(v), true
+	case uint32:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	}
+
+	return 0, false
+}
+
+func isOr(b *parser.BinaryExpr) bool {
+	return b.Op == "OR" || b.Op == "||"
+}
+
+// orOperands flattens "a OR b OR c" and returns all operands.
+func orOperands(e parser.Expr) []parser.Expr {
+	if b, ok := e.(*parser.BinaryExpr); ok && isOr(b) {
+		return append(orOperands(b.Left), orOperands(b.Right)...)
+	}
+
+	if p, ok := e.(*parser.ParenExpr); ok {
+		if b, ok := p.Expr.(*parser.BinaryExpr); ok && isOr(b) {
+			return orOperands(b)
+		}
+	}
+
+	return []parser.Expr{e}
+}
+
+func findMatches(e parser.Expr) []*parser.FuncCall {
+	var matches []*parser.FuncCall
+
+	if f, ok := e.(*parser.FuncCall); ok && strings.EqualFold(f.Name, "MATCH") {
+		matches = append(matches, f)
+	}
+
+	for _, child := range children(e) {
+		matches = append(matches, findMatches(child)...)
+	}
+
+	return matches
+}
+
+func children(e parser.Expr) []parser.Expr {
+	switch e := e.(type) {
+	case *parser.UnaryExpr:
+		return []parser.Expr{e.Expr}
+	case *parser.BinaryExpr:
+		return []parser.Expr{e.Left, e.Right}
+	case *parser.InExpr:
+		return append([]parser.Expr{e.Expr}, e.Values...)
+	case *parser.BetweenExpr:
+		return []parser.Expr{e.Expr, e.Lower, e.Upper}
+	case *parser.IsNullExpr:
+		return []parser.Expr{e.Expr}
+	case *parser.FuncCall:
+		return e.Args
+	case *parser.ParenExpr:
+		return []parser.Expr{e.Expr}
+	case *parser.TupleExpr:
+		return e.Items
+	case *parser.ObjectExpr:
+		return e.Items
+	case *parser.AliasExpr:
+		return []parser.Expr{e.Expr}
+	}
+
+	return nil
+}
+
+// startPos returns the position of the leftmost token of e.
+func startPos(e parser.Expr) int {
+	switch e := e.(type) {
+	case *parser.BinaryExpr:
+		return startPos(e.Left)
+	case *parser.InExpr:
+		return startPos(e.Expr)
+	case *parser.BetweenExpr:
+		return startPos(e.Expr)
+	case *parser.IsNullExpr:
+		return startPos(e.Expr)
+	case *parser.AliasExpr:
+		return startPos(e.Expr)
+	}
+
+	return e.Pos()
+}
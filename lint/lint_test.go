@@ -0,0 +1,220 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+	sphinxql "github.com/superjobru/go-sphinxql"
+	"github.com/superjobru/go-sphinxql/parser"
+)
+
+func ExampleLinter_Lint() {
+	linter := &Linter{
+		Schema: Schema{
+			FullTextFields: []string{"title"},
+		},
+	}
+
+	sb := sphinxql.NewSelectBuilder()
+	sb.Select("id").From("vacancies")
+	sb.Where(sb.Or(sb.Match("golang"), sb.Equal("remote", 1)))
+	sb.OrderBy("title")
+	sb.Limit(20).Offset(1000)
+
+	for _, issue := range linter.Lint(sb) {
+		fmt.Println(issue)
+	}
+
+	// Output:
+	// error at 41: match-or-filter: OR cannot mix MATCH with attribute filters; move the alternatives into the full-text query or run separate queries
+	// error at 65: order-by-full-text-field: cannot sort by full-text field title, which is not an attribute
+	// error at 71: limit-beyond-max-matches: LIMIT 1000,20 requires max_matches of at least 1020, but it's 1000; no row will be returned
+}
+
+func TestLintStatement(t *testing.T) {
+	linter := &Linter{
+		Schema: Schema{
+			FullTextFields: []string{"title", "content"},
+			Attributes: map[string]AttrType{
+				"city_id": AttrUint,
+				"name":    AttrString,
+			},
+		},
+		MaxMatches: 2000,
+	}
+
+	cases := map[string][]Issue{
+		"SELECT id FROM idx WHERE MATCH('a') AND city_id = 1 ORDER BY city_id LIMIT 1000,20": nil,
+		"SELECT id FROM idx WHERE MATCH('a') AND MATCH('b')": {
+			{Code: CodeMultipleMatch, Severity: SeverityError, Pos: 40},
+		},
+		"SELECT id FROM idx WHERE MATCH('a') OR MATCH('b')": {
+			{Code: CodeMatchInsideOr, Severity: SeverityError, Pos: 25},
+			{Code: CodeMultipleMatch, Severity: SeverityError, Pos: 39},
+			{Code: CodeMatchInsideOr, Severity: SeverityError, Pos: 39},
+		},
+		"SELECT id FROM idx WHERE city_id = 1 AND ((MATCH('a') AND name = 'x') OR city_id = 2)": {
+			{Code: CodeMatchOrFilter, Severity: SeverityError, Pos: 70},
+		},
+		"SELECT id FROM idx WHERE city_id = 1 OR city_id = 2": nil,
+		"SELECT id FROM idx OPTION ranker = expr('sum(lcs)')": {
+			{Code: CodeRankerExprWithoutMatch, Severity: SeverityWarning, Pos: 35},
+		},
+		"SELECT id FROM idx WHERE MATCH('a') OPTION ranker = expr('sum(lcs)')": nil,
+		"SELECT id FROM idx WHERE city_id LIKE '1%' AND name LIKE 'a%' AND unknown LIKE 'b%'": {
+			{Code: CodeLikeOnNonString, Severity: SeverityError, Pos: 33},
+		},
+		"SELECT id FROM idx GROUP BY city_id WITHIN GROUP ORDER BY Title ASC ORDER BY content DESC": {
+			{Code: CodeOrderByFullTextField, Severity: SeverityError, Pos: 58},
+			{Code: CodeOrderByFullTextField, Severity: SeverityError, Pos: 77},
+		},
+		"SELECT id FROM idx LIMIT 1990,20": {
+			{Code: CodeLimitBeyondMaxMatches, Severity: SeverityError, Pos: 19},
+		},
+		"SELECT id FROM idx LIMIT 1990,20 OPTION max_matches = 2010": nil,
+		"SELECT id FROM idx LIMIT 2500": {
+			{Code: CodeLimitBeyondMaxMatches, Severity: SeverityWarning, Pos: 19},
+		},
+		"SELECT id FROM idx LIMIT 0,2500": {
+			{Code: CodeLimitBeyondMaxMatches, Severity: SeverityWarning, Pos: 19},
+		},
+		"SELECT id FROM idx LIMIT 1500 OPTION max_matches = 1000": {
+			{Code: CodeLimitBeyondMaxMatches, Severity: SeverityWarning, Pos: 19},
+		},
+		"SELECT id FROM idx LIMIT 2000": nil,
+		"SELECT id FROM idx LIMIT 1990,20 OPTION max_matches = 2000": {
+			{Code: CodeLimitBeyondMaxMatches, Severity: SeverityError, Pos: 19},
+		},
+		"UPDATE idx SET city_id = 1 WHERE city_id LIKE 'x'": {
+			{Code: CodeLikeOnNonString, Severity: SeverityError, Pos: 41},
+		},
+		"DELETE FROM idx WHERE MATCH('a') AND MATCH('b')": {
+			{Code: CodeMultipleMatch, Severity: SeverityError, Pos: 37},
+		},
+		"INSERT INTO idx VALUES (1)": nil,
+	}
+
+	for query, expected := range cases {
+		t.Run(query, func(t *testing.T) {
+			a := assert.New(t)
+			stmt, err := parser.Parse(query)
+			a.NilError(err)
+
+			issues := linter.LintStatement(stmt)
+			a.Equal(len(issues), len(expected))
+
+			for i, issue := range issues {
+				a.Equal(issue.Code, expected[i].Code)
+				a.Equal(issue.Severity, expected[i].Severity)
+				a.Equal(issue.Pos, expected[i].Pos)
+			}
+		})
+	}
+}
+
+func TestLintHavingWithoutGroupBy(t *testing.T) {
+	a := assert.New(t)
+	stmt, err := parser.Parse("SELECT id FROM idx GROUP BY a HAVING cnt > 1")
+	a.NilError(err)
+
+	stmt.(*parser.SelectStmt).GroupBy = nil
+	issues := LintStatement(stmt)
+	a.Equal(len(issues), 1)
+	a.Equal(issues[0].Code, CodeHavingWithoutGroupBy)
+	a.Equal(issues[0].Pos, 37)
+}
+
+func TestLintBuilder(t *testing.T) {
+	a := assert.New(t)
+
+	// max_matches set by a placeholder is evaluated.
+	sb := sphinxql.NewSelectBuilder()
+	sb.Select("id").From("idx").Limit(20).Offset(1000)
+	sb.Option(sb.MaxMatches(5000))
+	a.Equal(len(Lint(sb)), 0)
+
+	// The policy of max_matches fixes the problem.
+	sb = sphinxql.NewSelectBuilder()
+	sb.Select("id").From("idx").Limit(20).Offset(1000)
+	a.Equal(len(Lint(sb)), 1)
+	sb.AutoMaxMatches(sphinxql.MaxMatchesPolicy{Enabled: true})
+	a.Equal(len(Lint(sb)), 0)
+
+	// Rows beyond max_matches are truncated even without offset.
+	sb = sphinxql.NewSelectBuilder()
+	sb.Select("id").From("idx").Limit(1500)
+	a.Equal(Lint(sb), []Issue{
+		{
+			Code:     CodeLimitBeyondMaxMatches,
+			Severity: SeverityWarning,
+			Pos:      19,
+			Message:  "LIMIT 1500 requires max_matches of at least 1500, but it's 1000; only 1000 rows will be returned",
+		},
+	})
+
+	sb.Offset(500)
+	a.Equal(Lint(sb)[0].Message, "LIMIT 500,1500 requires max_matches of at least 2000, but it's 1000; only 500 rows will be returned")
+	sb.AutoMaxMatches(sphinxql.MaxMatchesPolicy{Enabled: true})
+	a.Equal(len(Lint(sb)), 0)
+
+	// HAVING without GROUP BY is dropped from the SQL by the builder.
+	sb = sphinxql.NewSelectBuilder()
+	sb.Select("id").From("idx").Having("cnt > 1").Limit(20).Offset(1000)
+	a.Equal(Lint(sb), []Issue{
+		{
+			Code:     CodeHavingWithoutGroupBy,
+			Severity: SeverityError,
+			Pos:      0,
+			Message:  "HAVING requires GROUP BY; it's dropped from the query",
+		},
+		{
+			Code:     CodeLimitBeyondMaxMatches,
+			Severity: SeverityError,
+			Pos:      19,
+			Message:  "LIMIT 1000,20 requires max_matches of at least 1020, but it's 1000; no row will be returned",
+		},
+	})
+
+	// Hooks are not called by the linter.
+	called := false
+	sphinxql.AddHook(func(q *sphinxql.Query) { called = true })
+	defer sphinxql.ResetHooks()
+
+	sb = sphinxql.NewSelectBuilder()
+	sb.Select("id").From("idx")
+	a.Equal(len(Lint(sb)), 0)
+	a.Assert(!called)
+
+	issues := Lint(sphinxql.Build("SELECT id FROM idx HAVING cnt > 1"))
+	a.Equal(len(issues), 1)
+	a.Equal(issues[0].Code, CodeSyntax)
+	a.Equal(issues[0].Pos, 19)
+
+	issues = Lint(sphinxql.Build("SELECT id FROM idx WHERE"))
+	a.Equal(len(issues), 1)
+	a.Equal(issues[0].Code, CodeSyntax)
+	a.Equal(issues[0].Pos, 24)
+
+	ub := sphinxql.NewUpdateBuilder()
+	ub.Update("idx").Set(ub.Assign("a", 1)).Where(ub.Match("a"), ub.Match("b"))
+	a.Equal(len(lintStatementOf(t, ub)), 1)
+}
+
+func lintStatementOf(t *testing.T, b sphinxql.Builder) []Issue {
+	sql, args := b.Build()
+	stmt, err := parser.Parse(sql)
+	assert.New(t).NilError(err)
+	return LintStatement(stmt, args...)
+}
+
+func TestSeverityString(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(SeverityWarning.String(), "warning")
+	a.Equal(SeverityError.String(), "error")
+	a.Equal(Severity(9).String(), "<invalid severity 9>")
+}
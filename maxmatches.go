@@ -0,0 +1,157 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxMatches is the default value of max_matches OPTION in searchd.
+const DefaultMaxMatches = 1000
+
+// MaxMatchesPolicy controls whether `SelectBuilder` adjusts max_matches OPTION for offset paging.
+//
+// The searchd keeps only max_matches best matches, so "LIMIT 1000,20" returns nothing
+// with the default max_matches. If the policy is enabled, max_matches is set to
+// at least offset+limit when it's required.
+type MaxMatchesPolicy struct {
+	// Enabled turns the adjustment on.
+	Enabled bool
+
+	// Threshold is the max_matches value of the server.
+	// The OPTION is added only if offset+limit exceeds it.
+	// Zero means DefaultMaxMatches.
+	Threshold int
+
+	// Cap is the upper bound of automatically set max_matches.
+	// Zero means no bound.
+	Cap int
+}
+
+// SetMaxMatchesPolicy sets the default max_matches policy for all SELECT builders with flavor f.
+// It can be overridden per builder by `SelectBuilder#AutoMaxMatches`.
+//
+// SetMaxMatchesPolicy is expected to be called once during initialization.
+func (f Flavor) SetMaxMatchesPolicy(policy MaxMatchesPolicy) {
+	f.updateSettings(func(fs *flavorSettings) {
+		fs.maxMatchesPolicy = policy
+	})
+}
+
+// MaxMatchesPolicy returns the default max_matches policy of flavor f.
+func (f Flavor) MaxMatchesPolicy() MaxMatchesPolicy {
+	return f.settings().maxMatchesPolicy
+}
+
+// AutoMaxMatches sets the max_matches policy of sb, which overrides the policy of the flavor.
+func (sb *SelectBuilder) AutoMaxMatches(policy MaxMatchesPolicy) *SelectBuilder {
+	sb.maxMatchesPolicy = &policy
+	return sb
+}
+
+// buildOptionExprs returns OPTION expressions with max_matches adjusted according to the policy.
+// An explicit max_matches set by caller is raised instead of being duplicated.
+func (sb *SelectBuilder) buildOptionExprs(flavor Flavor) []string {
+	policy := flavor.MaxMatchesPolicy()
+
+	if sb.maxMatchesPolicy != nil {
+		policy = *sb.maxMatchesPolicy
+	}
+
+	if !policy.Enabled || sb.limit < 0 {
+		return sb.optionExprs
+	}
+
+	required := sb.limit
+
+	if sb.offset > 0 {
+		required += sb.offset
+	}
+
+	current := policy.Threshold
+
+	if current <= 0 {
+		current = DefaultMaxMatches
+	}
+
+	idx := -1
+
+	for i, e := range sb.optionExprs {
+		if optionName(e) != "max_matches" {
+			continue
+		}
+
+		v, ok := sb.optionIntValue(e)
+
+		if !ok {
+			// Don't touch the value which cannot be understood.
+			return sb.optionExprs
+		}
+
+		idx = i
+		current = v
+		break
+	}
+
+	if policy.Cap > 0 && required > policy.Cap {
+		required = policy.Cap
+	}
+
+	if required <= current {
+		return sb.optionExprs
+	}
+
+	expr := "max_matches = " + strconv.Itoa(required)
+	exprs := make([]string, 0, len(sb.optionExprs)+1)
+	exprs = append(exprs, sb.optionExprs...)
+
+	if idx >= 0 {
+		exprs[idx] = expr
+	} else {
+		exprs = append(exprs, expr)
+	}
+
+	return exprs
+}
+
+// optionIntValue returns the integer value of an OPTION expression like "name = 10" or "name = $0".
+func (sb *SelectBuilder) optionIntValue(expr string) (int, bool) {
+	i := strings.IndexByte(expr, '=')
+
+	if i < 0 {
+		return 0, false
+	}
+
+	value := strings.TrimSpace(expr[i+1:])
+
+	if strings.HasPrefix(value, "$") {
+		idx, err := strconv.Atoi(value[1:])
+
+		if err != nil || idx >= len(sb.args.args) {
+			return 0, false
+		}
+
+		switch v := sb.args.args[idx].(type) {
+		case int:
+			return v, true
+		case int32:
+			return int(v), true
+		case int64:
+			return int(v), true
+		case uint:
+			return int(v), true
+		case uint32:
+			return int(v), true
+		case uint64:
+			return int(v), true
+		}
+
+		return 0, false
+	}
+
+	v, err := strconv.Atoi(value)
+	return v, err == nil
+}
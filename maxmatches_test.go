@@ -0,0 +1,105 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func ExampleSelectBuilder_AutoMaxMatches() {
+	sb := NewSelectBuilder()
+	sb.Select("id")
+	sb.From("vacancies")
+	sb.Limit(20).Offset(1000)
+	sb.AutoMaxMatches(MaxMatchesPolicy{Enabled: true})
+
+	fmt.Println(sb)
+
+	// Output:
+	// SELECT id FROM vacancies LIMIT 1000,20 OPTION max_matches = 1020
+}
+
+func TestSelectBuilderAutoMaxMatches(t *testing.T) {
+	a := assert.New(t)
+	enabled := MaxMatchesPolicy{Enabled: true}
+	cases := []struct {
+		policy  MaxMatchesPolicy
+		limit   int
+		offset  int
+		options func(sb *SelectBuilder) []string
+		sql     string
+	}{
+		{
+			MaxMatchesPolicy{}, 20, 1000, nil,
+			"SELECT id FROM t LIMIT 1000,20",
+		},
+		{
+			enabled, 20, 980, nil,
+			"SELECT id FROM t LIMIT 980,20",
+		},
+		{
+			enabled, 20, -1, nil,
+			"SELECT id FROM t LIMIT 20",
+		},
+		{
+			enabled, 2000, -1, nil,
+			"SELECT id FROM t LIMIT 2000 OPTION max_matches = 2000",
+		},
+		{
+			MaxMatchesPolicy{Enabled: true, Threshold: 5000}, 20, 1000, nil,
+			"SELECT id FROM t LIMIT 1000,20",
+		},
+		{
+			MaxMatchesPolicy{Enabled: true, Cap: 1500}, 20, 3000, nil,
+			"SELECT id FROM t LIMIT 3000,20 OPTION max_matches = 1500",
+		},
+		{
+			enabled, 20, 3000, func(sb *SelectBuilder) []string { return []string{sb.Comment("a"), sb.MaxMatches(2000)} },
+			"SELECT id FROM t LIMIT 3000,20 OPTION comment = ?, max_matches = 3020",
+		},
+		{
+			enabled, 20, 3000, func(sb *SelectBuilder) []string { return []string{sb.MaxMatches(5000)} },
+			"SELECT id FROM t LIMIT 3000,20 OPTION max_matches = ?",
+		},
+		{
+			enabled, 20, 3000, func(sb *SelectBuilder) []string { return []string{"max_matches=100"} },
+			"SELECT id FROM t LIMIT 3000,20 OPTION max_matches = 3020",
+		},
+		{
+			enabled, 20, 3000, func(sb *SelectBuilder) []string { return []string{"max_matches = @var"} },
+			"SELECT id FROM t LIMIT 3000,20 OPTION max_matches = @var",
+		},
+	}
+
+	for i, c := range cases {
+		a.Use(&i, &c)
+		sb := NewSelectBuilder().Select("id").From("t").Limit(c.limit).Offset(c.offset)
+		sb.AutoMaxMatches(c.policy)
+
+		if c.options != nil {
+			sb.Option(c.options(sb)...)
+		}
+
+		a.Equal(sb.String(), c.sql)
+	}
+}
+
+func TestFlavorMaxMatchesPolicy(t *testing.T) {
+	a := assert.New(t)
+	old := SphinxSearch.MaxMatchesPolicy()
+	defer SphinxSearch.SetMaxMatchesPolicy(old)
+
+	SphinxSearch.SetMaxMatchesPolicy(MaxMatchesPolicy{Enabled: true, Threshold: 100})
+	a.Equal(SphinxSearch.MaxMatchesPolicy(), MaxMatchesPolicy{Enabled: true, Threshold: 100})
+
+	sb := NewSelectBuilder().Select("id").From("t").Limit(20).Offset(100)
+	a.Equal(sb.String(), "SELECT id FROM t LIMIT 100,20 OPTION max_matches = 120")
+
+	sb.AutoMaxMatches(MaxMatchesPolicy{})
+	a.Equal(sb.String(), "SELECT id FROM t LIMIT 100,20")
+}
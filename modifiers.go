@@ -5,6 +5,7 @@
 package sphinxql
 
 import (
+	"database/sql/driver"
 	"reflect"
 	"strings"
 )
@@ -64,6 +65,16 @@ func flatten(v reflect.Value) (elem interface{}, flattened []interface{}) {
 	return
 }
 
+// cloneStrings returns a copy of s.
+// A nil s is kept nil.
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+
+	return append(make([]string, 0, len(s)), s...)
+}
+
 type rawArgs struct {
 	expr string
 }
@@ -83,6 +94,57 @@ func List(arg interface{}) interface{} {
 	return listArgs{Flatten(arg)}
 }
 
+// matchOperators are characters which have special meaning in a full-text query.
+const matchOperators = `\()|-!@~"&/^$=<*'`
+
+// EscapeMatch escapes full-text operators in text with "\", so that text is matched literally in MATCH.
+//
+// The result is still a value which must be quoted as a string literal, which doubles every "\".
+// E.g. a text "a-b" is escaped to "a\-b" and then quoted as 'a\\-b'.
+func EscapeMatch(text string) string {
+	if !strings.ContainsAny(text, matchOperators) {
+		return text
+	}
+
+	buf := &strings.Builder{}
+	buf.Grow(len(text) + 8)
+
+	for i := 0; i < len(text); i++ {
+		if strings.IndexByte(matchOperators, text[i]) >= 0 {
+			buf.WriteByte('\\')
+		}
+
+		buf.WriteByte(text[i])
+	}
+
+	return buf.String()
+}
+
+type matchTextArgs struct {
+	text string
+}
+
+// Value implements `driver.Valuer`, so that the escaped text can be passed to `database/sql`.
+func (ma matchTextArgs) Value() (driver.Value, error) {
+	return EscapeMatch(ma.text), nil
+}
+
+type sensitiveArgs struct {
+	arg interface{}
+}
+
+// Sensitive marks arg as a sensitive value like a password or personal data.
+// It's compiled and interpolated as arg itself, but `Query#RedactedArgs` hides it,
+// so that hooks can log queries without leaking the value.
+func Sensitive(arg interface{}) interface{} {
+	return sensitiveArgs{arg}
+}
+
+// Value implements `driver.Valuer`, so that a sensitive arg can be passed to `database/sql` as is.
+func (sa sensitiveArgs) Value() (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(sa.arg)
+}
+
 type namedArgs struct {
 	name string
 	arg  interface{}
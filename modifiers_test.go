@@ -31,6 +31,22 @@ func TestEscape(t *testing.T) {
 	a.Equal(actuals, expects)
 }
 
+func TestEscapeMatch(t *testing.T) {
+	a := assert.New(t)
+	cases := map[string]string{
+		"golang developer": "golang developer",
+		"c++ -java":        "c++ \\-java",
+		`@title "a|b"`:     `\@title \"a\|b\"`,
+		`\(*)`:             `\\\(\*\)`,
+		"it's $5 = ^x<y!":  `it\'s \$5 \= \^x\<y\!`,
+	}
+
+	for text, expected := range cases {
+		a.Use(&text, &expected)
+		a.Equal(EscapeMatch(text), expected)
+	}
+}
+
 func TestFlatten(t *testing.T) {
 	a := assert.New(t)
 	cases := [][2]interface{}{
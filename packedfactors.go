@@ -0,0 +1,377 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPackedFactors means that a PACKEDFACTORS() value cannot be decoded.
+var ErrInvalidPackedFactors = errors.New("go-sphinxql: invalid packed factors")
+
+// PackedFactors returns a "PACKEDFACTORS()" expression in the default text format.
+// Use `PackedFactors` type to decode the value.
+func (sb *SelectBuilder) PackedFactors() string {
+	return "PACKEDFACTORS()"
+}
+
+// PackedFactorsJSON returns a "PACKEDFACTORS({json=1})" expression in JSON format.
+// Use `PackedFactors` type to decode the value.
+func (sb *SelectBuilder) PackedFactorsJSON() string {
+	return "PACKEDFACTORS({json=1})"
+}
+
+// PackedFactors is a decoded value of PACKEDFACTORS().
+// Both the default text format and JSON format are supported.
+//
+// PackedFactors implements `sql.Scanner`, so it can be used in `Row#Scan` directly.
+type PackedFactors struct {
+	Document DocumentFactors
+	Fields   []FieldFactors
+	Terms    []TermFactors
+}
+
+// DocumentFactors contains document-level ranking factors.
+type DocumentFactors struct {
+	BM25           int
+	BM25A          float64
+	FieldMask      uint64
+	DocWordCount   int
+	QueryWordCount int
+}
+
+// FieldFactors contains ranking factors of a matched field.
+type FieldFactors struct {
+	Field          int
+	LCS            int
+	HitCount       int
+	WordCount      int
+	TFIDF          float64
+	MinIDF         float64
+	MaxIDF         float64
+	SumIDF         float64
+	MinHitPos      int
+	MinBestSpanPos int
+	ExactHit       bool
+	ExactFieldHit  bool
+	ExactOrder     bool
+	MaxWindowHits  int
+	MinGaps        int
+	LCCS           int
+	WLCCS          float64
+	ATC            float64
+}
+
+// TermFactors contains ranking factors of a query term.
+type TermFactors struct {
+	Term int
+	TF   int
+	IDF  float64
+}
+
+var _ sql.Scanner = new(PackedFactors)
+
+// ParsePackedFactors decodes a PACKEDFACTORS() value in either text or JSON format.
+func ParsePackedFactors(s string) (*PackedFactors, error) {
+	pf := &PackedFactors{}
+
+	if err := pf.decode(s); err != nil {
+		return nil, err
+	}
+
+	return pf, nil
+}
+
+// Scan implements `sql.Scanner`.
+func (pf *PackedFactors) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*pf = PackedFactors{}
+		return nil
+	case []byte:
+		return pf.decode(string(v))
+	case string:
+		return pf.decode(v)
+	}
+
+	return fmt.Errorf("%w: unsupported type %T", ErrInvalidPackedFactors, src)
+}
+
+func (pf *PackedFactors) decode(s string) error {
+	s = strings.TrimSpace(s)
+	*pf = PackedFactors{}
+
+	if s == "" {
+		return nil
+	}
+
+	if s[0] == '{' {
+		return pf.decodeJSON(s)
+	}
+
+	return pf.decodeText(s)
+}
+
+func (pf *PackedFactors) decodeJSON(s string) error {
+	var raw map[string]json.RawMessage
+	d := json.NewDecoder(strings.NewReader(s))
+	d.UseNumber()
+
+	if err := d.Decode(&raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPackedFactors, err)
+	}
+
+	for k, v := range raw {
+		switch k {
+		case "fields":
+			var fields []map[string]json.Number
+
+			if err := json.Unmarshal(v, &fields); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidPackedFactors, err)
+			}
+
+			for i, f := range fields {
+				ff := FieldFactors{Field: i}
+
+				for name, value := range f {
+					if err := ff.set(name, value.String()); err != nil {
+						return err
+					}
+				}
+
+				pf.Fields = append(pf.Fields, ff)
+			}
+
+		case "words":
+			var words []map[string]json.Number
+
+			if err := json.Unmarshal(v, &words); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidPackedFactors, err)
+			}
+
+			for i, w := range words {
+				tf := TermFactors{Term: i}
+
+				for name, value := range w {
+					if err := tf.set(name, value.String()); err != nil {
+						return err
+					}
+				}
+
+				pf.Terms = append(pf.Terms, tf)
+			}
+
+		default:
+			var n json.Number
+
+			if err := json.Unmarshal(v, &n); err != nil {
+				// Ignore unknown non-numeric factors.
+				continue
+			}
+
+			if err := pf.Document.set(k, n.String()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeText decodes the default text format like
+// "bm25=616, bm25a=0.69, field_mask=2, field1=(lcs=1, hit_count=1), word0=(tf=1, idf=0.24)".
+func (pf *PackedFactors) decodeText(s string) error {
+	for len(s) > 0 {
+		eq := strings.IndexByte(s, '=')
+
+		if eq <= 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidPackedFactors, s)
+		}
+
+		name := strings.TrimSpace(s[:eq])
+		s = strings.TrimSpace(s[eq+1:])
+		var value string
+
+		if strings.HasPrefix(s, "(") {
+			end := strings.IndexByte(s, ')')
+
+			if end < 0 {
+				return fmt.Errorf("%w: unclosed group %q", ErrInvalidPackedFactors, name)
+			}
+
+			value = s[1:end]
+			s = s[end+1:]
+		} else if end := strings.IndexByte(s, ','); end >= 0 {
+			value = s[:end]
+			s = s[end:]
+		} else {
+			value = s
+			s = ""
+		}
+
+		s = strings.TrimPrefix(strings.TrimSpace(s), ",")
+		s = strings.TrimSpace(s)
+
+		if err := pf.setText(name, strings.TrimSpace(value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (pf *PackedFactors) setText(name, value string) error {
+	if idx, ok := indexedName(name, "field"); ok {
+		ff := FieldFactors{Field: idx}
+		err := eachTextFactor(value, ff.set)
+		pf.Fields = append(pf.Fields, ff)
+		return err
+	}
+
+	if idx, ok := indexedName(name, "word"); ok {
+		tf := TermFactors{Term: idx}
+		err := eachTextFactor(value, tf.set)
+		pf.Terms = append(pf.Terms, tf)
+		return err
+	}
+
+	return pf.Document.set(name, value)
+}
+
+func indexedName(name, prefix string) (int, bool) {
+	if !strings.HasPrefix(name, prefix) {
+		return 0, false
+	}
+
+	idx, err := strconv.Atoi(name[len(prefix):])
+	return idx, err == nil
+}
+
+func eachTextFactor(group string, set func(name, value string) error) error {
+	for _, pair := range strings.Split(group, ",") {
+		pair = strings.TrimSpace(pair)
+
+		if pair == "" {
+			continue
+		}
+
+		eq := strings.IndexByte(pair, '=')
+
+		if eq <= 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidPackedFactors, pair)
+		}
+
+		if err := set(strings.TrimSpace(pair[:eq]), strings.TrimSpace(pair[eq+1:])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (df *DocumentFactors) set(name, value string) (err error) {
+	switch name {
+	case "bm25":
+		df.BM25, err = parseIntFactor(name, value)
+	case "bm25a":
+		df.BM25A, err = parseFloatFactor(name, value)
+	case "field_mask":
+		df.FieldMask, err = strconv.ParseUint(value, 10, 64)
+
+		if err != nil {
+			err = fmt.Errorf("%w: %s=%s", ErrInvalidPackedFactors, name, value)
+		}
+	case "doc_word_count":
+		df.DocWordCount, err = parseIntFactor(name, value)
+	case "query_word_count":
+		df.QueryWordCount, err = parseIntFactor(name, value)
+	}
+
+	return
+}
+
+func (ff *FieldFactors) set(name, value string) (err error) {
+	switch name {
+	case "field":
+		ff.Field, err = parseIntFactor(name, value)
+	case "lcs":
+		ff.LCS, err = parseIntFactor(name, value)
+	case "hit_count":
+		ff.HitCount, err = parseIntFactor(name, value)
+	case "word_count":
+		ff.WordCount, err = parseIntFactor(name, value)
+	case "tf_idf":
+		ff.TFIDF, err = parseFloatFactor(name, value)
+	case "min_idf":
+		ff.MinIDF, err = parseFloatFactor(name, value)
+	case "max_idf":
+		ff.MaxIDF, err = parseFloatFactor(name, value)
+	case "sum_idf":
+		ff.SumIDF, err = parseFloatFactor(name, value)
+	case "min_hit_pos":
+		ff.MinHitPos, err = parseIntFactor(name, value)
+	case "min_best_span_pos":
+		ff.MinBestSpanPos, err = parseIntFactor(name, value)
+	case "exact_hit":
+		ff.ExactHit, err = parseBoolFactor(name, value)
+	case "exact_field_hit":
+		ff.ExactFieldHit, err = parseBoolFactor(name, value)
+	case "exact_order":
+		ff.ExactOrder, err = parseBoolFactor(name, value)
+	case "max_window_hits":
+		ff.MaxWindowHits, err = parseIntFactor(name, value)
+	case "min_gaps":
+		ff.MinGaps, err = parseIntFactor(name, value)
+	case "lccs":
+		ff.LCCS, err = parseIntFactor(name, value)
+	case "wlccs":
+		ff.WLCCS, err = parseFloatFactor(name, value)
+	case "atc":
+		ff.ATC, err = parseFloatFactor(name, value)
+	}
+
+	return
+}
+
+func (tf *TermFactors) set(name, value string) (err error) {
+	switch name {
+	case "tf":
+		tf.TF, err = parseIntFactor(name, value)
+	case "idf":
+		tf.IDF, err = parseFloatFactor(name, value)
+	}
+
+	return
+}
+
+func parseIntFactor(name, value string) (int, error) {
+	i, err := strconv.Atoi(value)
+
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s=%s", ErrInvalidPackedFactors, name, value)
+	}
+
+	return i, nil
+}
+
+func parseFloatFactor(name, value string) (float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s=%s", ErrInvalidPackedFactors, name, value)
+	}
+
+	return f, nil
+}
+
+func parseBoolFactor(name, value string) (bool, error) {
+	i, err := parseIntFactor(name, value)
+	return i != 0, err
+}
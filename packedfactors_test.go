@@ -0,0 +1,101 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func ExampleSelectBuilder_PackedFactorsJSON() {
+	sb := NewSelectBuilder()
+	sb.Select("id", sb.As(sb.PackedFactorsJSON(), "factors"))
+	sb.From("vacancies")
+	sb.Where(sb.Match("golang"))
+	sb.Option(sb.ExportRanker("bm25"))
+
+	s, args := sb.Build()
+	fmt.Println(s)
+	fmt.Println(args)
+
+	// Output:
+	// SELECT id, PACKEDFACTORS({json=1}) AS factors FROM vacancies WHERE MATCH(?) OPTION ranker = export(?)
+	// [golang bm25]
+}
+
+func TestPackedFactors(t *testing.T) {
+	a := assert.New(t)
+	expected := &PackedFactors{
+		Document: DocumentFactors{
+			BM25:         569,
+			BM25A:        0.617197,
+			FieldMask:    2,
+			DocWordCount: 2,
+		},
+		Fields: []FieldFactors{
+			{
+				Field:          1,
+				LCS:            1,
+				HitCount:       2,
+				WordCount:      2,
+				TFIDF:          0.152356,
+				MinIDF:         -0.062982,
+				MaxIDF:         0.215338,
+				SumIDF:         0.152356,
+				MinHitPos:      4,
+				MinBestSpanPos: 4,
+				ExactHit:       false,
+				MaxWindowHits:  1,
+				MinGaps:        2,
+				ExactOrder:     true,
+				LCCS:           1,
+				WLCCS:          0.215338,
+				ATC:            -0.003974,
+			},
+		},
+		Terms: []TermFactors{
+			{Term: 0, TF: 1, IDF: -0.062982},
+			{Term: 1, TF: 1, IDF: 0.215338},
+		},
+	}
+	cases := []string{
+		"bm25=569, bm25a=0.617197, field_mask=2, doc_word_count=2, field1=(lcs=1, hit_count=2, word_count=2, tf_idf=0.152356, min_idf=-0.062982, max_idf=0.215338, sum_idf=0.152356, min_hit_pos=4, min_best_span_pos=4, exact_hit=0, max_window_hits=1, min_gaps=2, exact_order=1, lccs=1, wlccs=0.215338, atc=-0.003974), word0=(tf=1, idf=-0.062982), word1=(tf=1, idf=0.215338)",
+		`{"bm25":569, "bm25a":0.617197, "field_mask":2, "doc_word_count":2, "fields":[{"field":1, "lcs":1, "hit_count":2, "word_count":2, "tf_idf":0.152356, "min_idf":-0.062982, "max_idf":0.215338, "sum_idf":0.152356, "min_hit_pos":4, "min_best_span_pos":4, "exact_hit":0, "max_window_hits":1, "min_gaps":2, "exact_order":1, "lccs":1, "wlccs":0.215338, "atc":-0.003974}], "words":[{"tf":1, "idf":-0.062982}, {"tf":1, "idf":0.215338}]}`,
+	}
+
+	for _, c := range cases {
+		a.Use(&c)
+
+		pf, err := ParsePackedFactors(c)
+		a.NilError(err)
+		a.Equal(pf, expected)
+
+		scanned := &PackedFactors{}
+		a.NilError(scanned.Scan([]byte(c)))
+		a.Equal(scanned, expected)
+	}
+
+	empty := &PackedFactors{Fields: []FieldFactors{{}}}
+	a.NilError(empty.Scan(nil))
+	a.Equal(empty, &PackedFactors{})
+
+	errCases := []interface{}{
+		"bm25=abc",
+		"field0=(lcs=1",
+		"garbage",
+		`{"bm25":`,
+		`{"fields":[{"lcs":"x"}]}`,
+		42,
+	}
+
+	for _, c := range errCases {
+		a.Use(&c)
+		err := new(PackedFactors).Scan(c)
+		a.Assert(errors.Is(err, ErrInvalidPackedFactors))
+	}
+}
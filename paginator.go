@@ -0,0 +1,245 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidCursor means that a cursor is malformed or it's created by another paginator.
+	ErrInvalidCursor = errors.New("go-sphinxql: invalid cursor")
+)
+
+// SeekColumn is a column of ORDER BY used in keyset pagination.
+type SeekColumn struct {
+	// Expr is the sorting expression, e.g. "id" or "WEIGHT()".
+	Expr string
+
+	// Alias is an optional alias of Expr.
+	// If it's set, "Expr AS Alias" is added to SELECT and the alias is used in ORDER BY and WHERE.
+	// It's required for expressions which cannot be used in WHERE directly, e.g. "WEIGHT()".
+	Alias string
+
+	// Desc sorts the column in descending order.
+	Desc bool
+}
+
+func (c SeekColumn) name() string {
+	if c.Alias != "" {
+		return c.Alias
+	}
+
+	return c.Expr
+}
+
+// Paginator implements keyset (seek) pagination.
+// Instead of an offset, the sorting values of the last row of the previous page are
+// turned into a WHERE condition, so deep pages are as fast as the first one
+// and max_matches doesn't grow with the page number.
+//
+// Paginator is immutable and can be shared among goroutines.
+type Paginator struct {
+	columns  []SeekColumn
+	pageSize int
+	key      uint32
+}
+
+type paginatorCursor struct {
+	Key    uint32        `json:"k"`
+	Values []interface{} `json:"v"`
+}
+
+// NewPaginator creates a new Paginator with page size and ORDER BY columns.
+// If there is no "id" column, an ascending "id" column is appended as a tiebreaker
+// to make the order of rows stable.
+func NewPaginator(pageSize int, column ...SeekColumn) *Paginator {
+	columns := make([]SeekColumn, 0, len(column)+1)
+	hasID := false
+
+	for _, c := range column {
+		if c.Expr == "id" {
+			hasID = true
+		}
+
+		columns = append(columns, c)
+	}
+
+	if !hasID {
+		columns = append(columns, SeekColumn{Expr: "id"})
+	}
+
+	h := fnv.New32a()
+
+	for _, c := range columns {
+		fmt.Fprintf(h, "%s %s %v;", c.Expr, c.Alias, c.Desc)
+	}
+
+	return &Paginator{
+		columns:  columns,
+		pageSize: pageSize,
+		key:      h.Sum32(),
+	}
+}
+
+// Columns returns the ORDER BY columns including the tiebreaker.
+// Values passed to `Paginator#Cursor` must follow this order.
+func (p *Paginator) Columns() []SeekColumn {
+	return append([]SeekColumn(nil), p.columns...)
+}
+
+// addSelectCol adds col to SELECT unless it's already there,
+// so that applying a paginator to a builder again doesn't duplicate columns.
+func (sb *SelectBuilder) addSelectCol(col string) {
+	for _, c := range sb.selectCols {
+		if c == col {
+			return
+		}
+	}
+
+	sb.selectCols = append(sb.selectCols, col)
+}
+
+// Apply sets ORDER BY, LIMIT and max_matches OPTION of sb for a page.
+// If cursor is not empty, a seek condition is added to WHERE to start after the row the cursor points to.
+// An empty cursor means the first page.
+//
+// As Select replaces all columns, Apply must be called after it.
+func (p *Paginator) Apply(sb *SelectBuilder, cursor string) error {
+	var values []interface{}
+
+	if cursor != "" {
+		var err error
+		values, err = p.decode(cursor)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	orderBy := make([]string, 0, len(p.columns))
+
+	for _, c := range p.columns {
+		if c.Alias != "" {
+			sb.addSelectCol(sb.As(c.Expr, c.Alias))
+		}
+
+		if c.Desc {
+			orderBy = append(orderBy, sb.Desc(c.name()))
+		} else {
+			orderBy = append(orderBy, sb.Asc(c.name()))
+		}
+	}
+
+	if values != nil {
+		sb.Where(p.seek(&sb.Cond, values))
+	}
+
+	sb.OrderBy(orderBy...)
+	sb.Offset(-1)
+	sb.Limit(p.pageSize)
+
+	// Seek doesn't skip any match, so there is no need to keep more matches than a page.
+	sb.setOption("max_matches", sb.MaxMatches(p.pageSize))
+	return nil
+}
+
+// seek builds "(c1 > v1 OR (c1 = v1 AND c2 > v2) OR ...)".
+func (p *Paginator) seek(c *Cond, values []interface{}) string {
+	ors := make([]string, 0, len(p.columns))
+
+	for i, col := range p.columns {
+		ands := make([]string, 0, i+1)
+
+		for j := 0; j < i; j++ {
+			ands = append(ands, c.Equal(p.columns[j].name(), values[j]))
+		}
+
+		if col.Desc {
+			ands = append(ands, c.LessThan(col.name(), values[i]))
+		} else {
+			ands = append(ands, c.GreaterThan(col.name(), values[i]))
+		}
+
+		if len(ands) == 1 {
+			ors = append(ors, ands[0])
+		} else {
+			ors = append(ors, c.And(ands...))
+		}
+	}
+
+	if len(ors) == 1 {
+		return ors[0]
+	}
+
+	return c.Or(ors...)
+}
+
+// Cursor creates an opaque cursor pointing to a row.
+// The values are the sorting values of the last row of a page in the order of `Paginator#Columns`.
+func (p *Paginator) Cursor(value ...interface{}) (string, error) {
+	if len(value) != len(p.columns) {
+		return "", fmt.Errorf("%w: expected %d values, got %d", ErrInvalidCursor, len(p.columns), len(value))
+	}
+
+	data, err := json.Marshal(paginatorCursor{
+		Key:    p.key,
+		Values: value,
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func (p *Paginator) decode(cursor string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	var pc paginatorCursor
+	d := json.NewDecoder(strings.NewReader(string(data)))
+	d.UseNumber()
+
+	if err := d.Decode(&pc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	if pc.Key != p.key || len(pc.Values) != len(p.columns) {
+		return nil, ErrInvalidCursor
+	}
+
+	// A cursor comes from a client, so only values created by Cursor are accepted.
+	// Objects, arrays, null and bools would be passed to a driver as is.
+	for i, v := range pc.Values {
+		switch v := v.(type) {
+		case string:
+		case json.Number:
+			if iv, err := v.Int64(); err == nil {
+				pc.Values[i] = iv
+			} else if uv, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+				pc.Values[i] = uv
+			} else if fv, err := v.Float64(); err == nil {
+				pc.Values[i] = fv
+			} else {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unexpected value %v", ErrInvalidCursor, v)
+		}
+	}
+
+	return pc.Values, nil
+}
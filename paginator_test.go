@@ -0,0 +1,119 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func ExamplePaginator() {
+	p := NewPaginator(20, SeekColumn{Expr: "WEIGHT()", Alias: "w", Desc: true})
+
+	// The first page.
+	sb := NewSelectBuilder()
+	sb.Select("id")
+	sb.From("vacancies")
+	sb.Where(sb.Match("golang"))
+	_ = p.Apply(sb, "")
+	fmt.Println(sb)
+
+	// The next page starts after the last row of the previous one.
+	cursor, _ := p.Cursor(1500, 42)
+
+	sb = NewSelectBuilder()
+	sb.Select("id")
+	sb.From("vacancies")
+	sb.Where(sb.Match("golang"))
+	_ = p.Apply(sb, cursor)
+
+	s, args := sb.Build()
+	fmt.Println(s)
+	fmt.Println(args)
+
+	// Output:
+	// SELECT id, WEIGHT() AS w FROM vacancies WHERE MATCH(?) ORDER BY w DESC, id ASC LIMIT 20 OPTION max_matches = ?
+	// SELECT id, WEIGHT() AS w FROM vacancies WHERE MATCH(?) AND (w < ? OR (w = ? AND id > ?)) ORDER BY w DESC, id ASC LIMIT 20 OPTION max_matches = ?
+	// [golang 1500 1500 42 20]
+}
+
+func TestPaginator(t *testing.T) {
+	a := assert.New(t)
+	p := NewPaginator(10, SeekColumn{Expr: "salary", Desc: true}, SeekColumn{Expr: "id"})
+	a.Equal(p.Columns(), []SeekColumn{{Expr: "salary", Desc: true}, {Expr: "id"}})
+
+	cursor, err := p.Cursor(150.5, "abc")
+	a.NilError(err)
+
+	sb := NewSelectBuilder().Select("id").From("vacancies")
+	sb.Limit(100).Offset(2000)
+	sb.Option(sb.MaxMatches(3000), sb.Comment("deep"))
+	a.NilError(p.Apply(sb, cursor))
+
+	s, args := sb.Build()
+	a.Equal(s, "SELECT id FROM vacancies WHERE (salary < ? OR (salary = ? AND id > ?)) ORDER BY salary DESC, id ASC LIMIT 10 OPTION max_matches = ?, comment = ?")
+	a.Equal(args, []interface{}{150.5, 150.5, "abc", 10, "deep"})
+
+	cursor, err = p.Cursor(int64(1)<<60, 7)
+	a.NilError(err)
+	sb = NewSelectBuilder().Select("id").From("vacancies")
+	a.NilError(p.Apply(sb, cursor))
+	_, args = sb.Build()
+	a.Equal(args, []interface{}{int64(1) << 60, int64(1) << 60, int64(7), 10})
+
+	// A uint64 key beyond int64 must not lose precision.
+	cursor, err = p.Cursor(1, uint64(math.MaxUint64))
+	a.NilError(err)
+	sb = NewSelectBuilder().Select("id").From("vacancies")
+	a.NilError(p.Apply(sb, cursor))
+	_, args = sb.Build()
+	a.Equal(args, []interface{}{int64(1), int64(1), uint64(math.MaxUint64), 10})
+
+	_, err = p.Cursor(1)
+	a.Assert(errors.Is(err, ErrInvalidCursor))
+
+	other := NewPaginator(10, SeekColumn{Expr: "salary"})
+	cursor, err = other.Cursor(1, 2)
+	a.NilError(err)
+
+	for _, c := range []string{cursor, "!!!", "e30"} {
+		a.Use(&c)
+		err = p.Apply(NewSelectBuilder(), c)
+		a.Assert(errors.Is(err, ErrInvalidCursor))
+	}
+
+	// Forged cursors with a valid key must carry numbers and strings only.
+	for _, values := range []string{`[{"a":1},2]`, `[[1],2]`, `[null,2]`, `[true,2]`, `[1e999,2]`} {
+		forged := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"k":%d,"v":%s}`, p.key, values)))
+		a.Use(&values)
+		err = p.Apply(NewSelectBuilder(), forged)
+		a.Assert(errors.Is(err, ErrInvalidCursor))
+	}
+}
+
+func TestPaginatorApplyTwice(t *testing.T) {
+	a := assert.New(t)
+	p := NewPaginator(10, SeekColumn{Expr: "WEIGHT()", Alias: "w", Desc: true})
+
+	sb := NewSelectBuilder().Select("id").From("vacancies")
+	sb.Where(sb.Match("golang"))
+	a.NilError(p.Apply(sb, ""))
+
+	cursor, err := p.Cursor(100, 5)
+	a.NilError(err)
+
+	clone := sb.Clone()
+	a.NilError(p.Apply(clone, cursor))
+	a.NilError(p.Apply(sb, cursor))
+
+	expected := "SELECT id, WEIGHT() AS w FROM vacancies WHERE MATCH(?) AND (w < ? OR (w = ? AND id > ?)) ORDER BY w DESC, id ASC LIMIT 10 OPTION max_matches = ?"
+	a.Equal(clone.String(), expected)
+	a.Equal(sb.String(), expected)
+}
@@ -0,0 +1,262 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package parser
+
+// Node is a node of the AST.
+type Node interface {
+	// String returns the node as SphinxQL.
+	String() string
+}
+
+// Statement is a parsed SphinxQL statement.
+type Statement interface {
+	Node
+	statement()
+}
+
+// Expr is an expression.
+type Expr interface {
+	Node
+
+	// Pos returns the byte offset of the expression in the parsed query.
+	// For operators, it's the offset of the operator.
+	// It's 0 for expressions created manually.
+	Pos() int
+
+	expr()
+}
+
+// node keeps the position of an expression.
+type node struct {
+	pos int
+}
+
+// Pos returns the byte offset of the expression in the parsed query.
+func (n node) Pos() int {
+	return n.pos
+}
+
+// SelectStmt is a SELECT statement.
+type SelectStmt struct {
+	Fields             []SelectField
+	From               []string
+	Where              Expr
+	GroupN             int
+	GroupBy            []Expr
+	WithinGroupOrderBy []OrderItem
+	Having             Expr
+	OrderBy            []OrderItem
+
+	// Limit is -1 if there is no LIMIT.
+	Limit int
+
+	// Offset is -1 if there is no offset in LIMIT.
+	Offset int
+
+	// LimitPos is the byte offset of LIMIT in the parsed query.
+	LimitPos int
+
+	Options []Option
+}
+
+// SelectField is a column of SELECT.
+type SelectField struct {
+	Expr  Expr
+	Alias string
+}
+
+// OrderItem is an expression of ORDER BY.
+type OrderItem struct {
+	Expr Expr
+
+	// Dir is "ASC", "DESC" or empty if the direction is not set explicitly.
+	Dir string
+}
+
+// Option is an item of OPTION.
+type Option struct {
+	Name  string
+	Value Expr
+}
+
+// InsertStmt is an INSERT or a REPLACE statement.
+type InsertStmt struct {
+	Replace bool
+	Table   string
+	Columns []string
+	Rows    [][]Expr
+}
+
+// UpdateStmt is an UPDATE statement.
+type UpdateStmt struct {
+	Table   string
+	Set     []Assignment
+	Where   Expr
+	Options []Option
+}
+
+// Assignment is an item of SET in UPDATE.
+type Assignment struct {
+	Column string
+	Value  Expr
+}
+
+// DeleteStmt is a DELETE statement.
+type DeleteStmt struct {
+	Table string
+	Where Expr
+}
+
+// CallStmt is a CALL statement, e.g. "CALL SNIPPETS(...)".
+type CallStmt struct {
+	Name string
+	Args []Expr
+}
+
+// Ident is an identifier, e.g. "id", "@count", "j.field" or "`order`".
+// Name keeps the identifier as is, including back quotes.
+type Ident struct {
+	node
+
+	Name string
+}
+
+// StringLit is a string literal.
+type StringLit struct {
+	node
+
+	Value string
+}
+
+// NumberLit is a numeric literal.
+// Value is int64, uint64 or float64.
+type NumberLit struct {
+	node
+
+	Text  string
+	Value interface{}
+}
+
+// NullLit is NULL.
+type NullLit struct {
+	node
+}
+
+// Param is a placeholder "?". Index is the 0-based position of the placeholder in the query.
+type Param struct {
+	node
+
+	Index int
+}
+
+// Star is "*" in "SELECT *" or "COUNT(*)".
+type Star struct {
+	node
+}
+
+// UnaryExpr is "op expr", e.g. "NOT expr" or "-expr".
+type UnaryExpr struct {
+	node
+
+	Op   string
+	Expr Expr
+}
+
+// BinaryExpr is "left op right", e.g. "a AND b" or "a >= 1".
+type BinaryExpr struct {
+	node
+
+	Op    string
+	Left  Expr
+	Right Expr
+}
+
+// InExpr is "expr [NOT] IN (values...)".
+type InExpr struct {
+	node
+
+	Expr   Expr
+	Not    bool
+	Values []Expr
+}
+
+// BetweenExpr is "expr [NOT] BETWEEN lower AND upper".
+type BetweenExpr struct {
+	node
+
+	Expr  Expr
+	Not   bool
+	Lower Expr
+	Upper Expr
+}
+
+// IsNullExpr is "expr IS [NOT] NULL".
+type IsNullExpr struct {
+	node
+
+	Expr Expr
+	Not  bool
+}
+
+// FuncCall is a function call, e.g. "MATCH('query')" or "COUNT(DISTINCT id)".
+type FuncCall struct {
+	node
+
+	Name     string
+	Distinct bool
+	Args     []Expr
+}
+
+// ParenExpr is "(expr)".
+type ParenExpr struct {
+	node
+
+	Expr Expr
+}
+
+// TupleExpr is "(expr1, expr2, ...)", e.g. an MVA value or a value of field_weights OPTION.
+type TupleExpr struct {
+	node
+
+	Items []Expr
+}
+
+// ObjectExpr is "{expr1, expr2, ...}", e.g. options of GEODIST or HIGHLIGHT.
+type ObjectExpr struct {
+	node
+
+	Items []Expr
+}
+
+// AliasExpr is "expr AS alias", e.g. an option of CALL SNIPPETS.
+type AliasExpr struct {
+	node
+
+	Expr  Expr
+	Alias string
+}
+
+func (*SelectStmt) statement() {}
+func (*InsertStmt) statement() {}
+func (*UpdateStmt) statement() {}
+func (*DeleteStmt) statement() {}
+func (*CallStmt) statement()   {}
+
+func (*Ident) expr()       {}
+func (*StringLit) expr()   {}
+func (*NumberLit) expr()   {}
+func (*NullLit) expr()     {}
+func (*Param) expr()       {}
+func (*Star) expr()        {}
+func (*UnaryExpr) expr()   {}
+func (*BinaryExpr) expr()  {}
+func (*InExpr) expr()      {}
+func (*BetweenExpr) expr() {}
+func (*IsNullExpr) expr()  {}
+func (*FuncCall) expr()    {}
+func (*ParenExpr) expr()   {}
+func (*TupleExpr) expr()   {}
+func (*ObjectExpr) expr()  {}
+func (*AliasExpr) expr()   {}
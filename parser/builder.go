@@ -0,0 +1,262 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"fmt"
+	"strconv"
+
+	sphinxql "github.com/superjobru/go-sphinxql"
+)
+
+// Builder converts stmt to a builder of package sphinxql.
+// Literals in expressions become args of the builder, while OPTION values and
+// objects like "{in=deg, out=km}" are kept inline.
+// Placeholders "?" in stmt are bound to params in order.
+//
+// The type of returned builder is *SelectBuilder, *InsertBuilder, *UpdateBuilder or *DeleteBuilder.
+// A CALL statement is converted to a builder created by `sphinxql.Build`.
+func Builder(stmt Statement, params ...interface{}) (sphinxql.Builder, error) {
+	switch s := stmt.(type) {
+	case *SelectStmt:
+		return s.SelectBuilder(params...)
+	case *InsertStmt:
+		return s.InsertBuilder(params...)
+	case *UpdateStmt:
+		return s.UpdateBuilder(params...)
+	case *DeleteStmt:
+		return s.DeleteBuilder(params...)
+	case *CallStmt:
+		return s.Builder(params...)
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedStatement, stmt)
+}
+
+// SelectBuilder converts s to a `sphinxql.SelectBuilder`.
+// See `Builder` for details.
+func (s *SelectStmt) SelectBuilder(params ...interface{}) (*sphinxql.SelectBuilder, error) {
+	sb := sphinxql.NewSelectBuilder()
+	p := &printer{vars: sb.Var, params: params}
+
+	cols := make([]string, 0, len(s.Fields))
+
+	for _, f := range s.Fields {
+		cols = append(cols, selectField(p, f))
+	}
+
+	sb.Select(cols...)
+	sb.From(sphinxql.EscapeAll(s.From...)...)
+
+	if s.Where != nil {
+		sb.Where(p.conds(s.Where)...)
+	}
+
+	if len(s.GroupBy) > 0 {
+		sb.GroupNBy(s.GroupN, p.subs(s.GroupBy)...)
+	}
+
+	if len(s.WithinGroupOrderBy) > 0 {
+		sb.WithinGroupOrderBy(p.orderExprs(s.WithinGroupOrderBy)...)
+	}
+
+	if s.Having != nil {
+		sb.Having(p.conds(s.Having)...)
+	}
+
+	if len(s.OrderBy) > 0 {
+		sb.OrderBy(p.orderExprs(s.OrderBy)...)
+	}
+
+	sb.Limit(s.Limit)
+	sb.Offset(s.Offset)
+
+	if len(s.Options) > 0 {
+		sb.Option(p.optionExprs(s.Options)...)
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return sb, nil
+}
+
+// InsertBuilder converts s to a `sphinxql.InsertBuilder`.
+// See `Builder` for details.
+func (s *InsertStmt) InsertBuilder(params ...interface{}) (*sphinxql.InsertBuilder, error) {
+	ib := sphinxql.NewInsertBuilder()
+
+	// Names are escaped by the setters.
+	if s.Replace {
+		ib.ReplaceInto(s.Table)
+	} else {
+		ib.InsertInto(s.Table)
+	}
+
+	if len(s.Columns) > 0 {
+		ib.Cols(s.Columns...)
+	}
+
+	for _, row := range s.Rows {
+		values := make([]interface{}, 0, len(row))
+
+		for _, e := range row {
+			switch v := e.(type) {
+			case *StringLit:
+				values = append(values, v.Value)
+			case *NumberLit:
+				values = append(values, v.Value)
+			default:
+				// Expressions like MVA "(1, 2, 3)" are nested as builders.
+				b, err := buildExpr("", e, params)
+
+				if err != nil {
+					return nil, err
+				}
+
+				values = append(values, b)
+			}
+		}
+
+		ib.Values(values...)
+	}
+
+	return ib, nil
+}
+
+// UpdateBuilder converts s to a `sphinxql.UpdateBuilder`.
+// See `Builder` for details.
+func (s *UpdateStmt) UpdateBuilder(params ...interface{}) (*sphinxql.UpdateBuilder, error) {
+	ub := sphinxql.NewUpdateBuilder()
+	ub.Update(s.Table)
+	p := &printer{vars: ub.Var, params: params}
+
+	assignments := make([]string, 0, len(s.Set))
+
+	for _, a := range s.Set {
+		assignments = append(assignments, sphinxql.Escape(a.Column)+" = "+p.sub(a.Value))
+	}
+
+	ub.Set(assignments...)
+
+	if s.Where != nil {
+		ub.Where(p.conds(s.Where)...)
+	}
+
+	if len(s.Options) > 0 {
+		ub.Option(p.optionExprs(s.Options)...)
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return ub, nil
+}
+
+// DeleteBuilder converts s to a `sphinxql.DeleteBuilder`.
+// See `Builder` for details.
+func (s *DeleteStmt) DeleteBuilder(params ...interface{}) (*sphinxql.DeleteBuilder, error) {
+	db := sphinxql.NewDeleteBuilder()
+	db.DeleteFrom(s.Table)
+	p := &printer{vars: db.Var, params: params}
+
+	if s.Where != nil {
+		db.Where(p.conds(s.Where)...)
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return db, nil
+}
+
+// Builder converts s to a builder created by `sphinxql.Build`.
+// See `Builder` for details.
+func (s *CallStmt) Builder(params ...interface{}) (sphinxql.Builder, error) {
+	return buildExpr("CALL ", &FuncCall{Name: s.Name, Args: s.Args}, params)
+}
+
+// buildExpr converts prefix and e to a builder created by `sphinxql.Build`.
+func buildExpr(prefix string, e Expr, params []interface{}) (sphinxql.Builder, error) {
+	var args []interface{}
+	p := &printer{
+		vars: func(value interface{}) string {
+			args = append(args, value)
+			return "$" + strconv.Itoa(len(args)-1)
+		},
+		params: params,
+	}
+	p.raw(prefix)
+	p.expr(e)
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return sphinxql.Build(p.buf.String(), args...), nil
+}
+
+// conds splits top-level AND of e, so every operand becomes a separate expression of WHERE or HAVING.
+func (p *printer) conds(e Expr) []string {
+	var operands []Expr
+	var split func(e Expr)
+
+	split = func(e Expr) {
+		if b, ok := e.(*BinaryExpr); ok && (b.Op == "AND" || b.Op == "&&") {
+			split(b.Left)
+			split(b.Right)
+			return
+		}
+
+		operands = append(operands, e)
+	}
+	split(e)
+
+	conds := make([]string, 0, len(operands))
+
+	for _, o := range operands {
+		// An OR operand must be parenthesized to be joined with AND.
+		if len(operands) > 1 && precedence(o) <= precOr {
+			conds = append(conds, "("+p.sub(o)+")")
+		} else {
+			conds = append(conds, p.sub(o))
+		}
+	}
+
+	return conds
+}
+
+func (p *printer) subs(exprs []Expr) []string {
+	s := make([]string, 0, len(exprs))
+
+	for _, e := range exprs {
+		s = append(s, p.sub(e))
+	}
+
+	return s
+}
+
+func (p *printer) orderExprs(items []OrderItem) []string {
+	s := make([]string, 0, len(items))
+
+	for _, item := range items {
+		s = append(s, p.orderItem(item))
+	}
+
+	return s
+}
+
+func (p *printer) optionExprs(options []Option) []string {
+	s := make([]string, 0, len(options))
+
+	for _, o := range options {
+		s = append(s, p.option(o))
+	}
+
+	return s
+}
@@ -0,0 +1,132 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/huandu/go-assert"
+	sphinxql "github.com/superjobru/go-sphinxql"
+)
+
+func TestBuilderRoundTrip(t *testing.T) {
+	a := assert.New(t)
+	cases := []string{
+		"SELECT * FROM idx",
+		"SELECT id, WEIGHT() AS w FROM idx1, idx2 WHERE MATCH('@title \"go\"') AND a = 1",
+		"SELECT id FROM idx WHERE a = 'x' OR b = 'y'",
+		"SELECT id FROM idx WHERE (a = 1 OR b > 2.5) AND NOT c IN (1, 2) AND d NOT BETWEEN -1 AND 1",
+		"SELECT COUNT(*) AS cnt FROM idx GROUP 3 BY company_id WITHIN GROUP ORDER BY WEIGHT() DESC HAVING cnt > 1 AND cnt < 10",
+		"SELECT id FROM idx ORDER BY a ASC, b DESC LIMIT 20,10 OPTION ranker = expr('sum(lcs)'), comment = 'it\\'s', max_matches = 2000",
+		"SELECT GEODIST(lat, lon, 0.5, 1, {in = deg, out = km}) AS d FROM idx WHERE d < 1000 LIMIT 5",
+		"INSERT INTO idx (id, title, tags) VALUES (1, 'x', (1, 2, 3)), (2, 'y', ())",
+		"REPLACE INTO idx VALUES (1, 'x', -1.5)",
+		"UPDATE idx SET price = price * 2, tags = (1, 2) WHERE id IN (1, 2) OPTION strict = 1",
+		"DELETE FROM idx WHERE id = 1 OR id = 2",
+		"CALL SNIPPETS('text', 'idx', 'query', 5 AS limit)",
+	}
+
+	for _, query := range cases {
+		stmt, err := Parse(query)
+		a.Use(&query)
+		a.NilError(err)
+
+		b, err := Builder(stmt)
+		a.NilError(err)
+
+		sql, args := b.Build()
+		interpolated, err := sphinxql.SphinxSearch.Interpolate(sql, args)
+		a.NilError(err)
+		a.Equal(interpolated, query)
+	}
+}
+
+func TestBuilderDollarNames(t *testing.T) {
+	a := assert.New(t)
+	cases := []string{
+		"SELECT `a$b` FROM `idx$1` WHERE `c$d` = 1",
+		"INSERT INTO `idx$1` (`a$b`) VALUES (1)",
+		"REPLACE INTO `idx$1` (`a$b`) VALUES (1)",
+		"UPDATE `idx$1` SET `a$b` = 1 WHERE `c$d` = 2",
+		"DELETE FROM `idx$1` WHERE `a$b` = 1",
+	}
+
+	for _, query := range cases {
+		stmt, err := Parse(query)
+		a.Use(&query)
+		a.NilError(err)
+
+		b, err := Builder(stmt)
+		a.NilError(err)
+
+		sql, args := b.Build()
+		interpolated, err := sphinxql.SphinxSearch.Interpolate(sql, args)
+		a.NilError(err)
+		a.Equal(interpolated, query)
+	}
+
+	_, err := Builder(nil)
+	a.Assert(errors.Is(err, ErrUnsupportedStatement))
+}
+
+func TestBuilderParams(t *testing.T) {
+	a := assert.New(t)
+	stmt, err := Parse("SELECT id FROM idx WHERE a = ? AND b IN (?, 3) AND c = '$x' OPTION comment = ?")
+	a.NilError(err)
+
+	sb, err := stmt.(*SelectStmt).SelectBuilder(1, 2, "c")
+	a.NilError(err)
+
+	sql, args := sb.Build()
+	a.Equal(sql, "SELECT id FROM idx WHERE a = ? AND b IN (?, ?) AND c = ? OPTION comment = ?")
+	a.Equal(args, []interface{}{1, 2, int64(3), "$x", "c"})
+
+	_, err = stmt.(*SelectStmt).SelectBuilder(1, 2)
+	a.Assert(errors.Is(err, ErrMissingParam))
+
+	stmt, err = Parse("INSERT INTO idx (id, tags) VALUES (?, (?, 2))")
+	a.NilError(err)
+
+	b, err := Builder(stmt, 1, 10)
+	a.NilError(err)
+
+	sql, args = b.Build()
+	a.Equal(sql, "INSERT INTO idx (id, tags) VALUES (?, (?, ?))")
+	a.Equal(args, []interface{}{1, 10, int64(2)})
+
+	for _, query := range []string{
+		"INSERT INTO idx VALUES (?)",
+		"UPDATE idx SET a = ?",
+		"UPDATE idx SET a = 1 WHERE id = ?",
+		"UPDATE idx SET a = 1 OPTION comment = ?",
+		"DELETE FROM idx WHERE id = ?",
+		"CALL f(?)",
+	} {
+		stmt, err := Parse(query)
+		a.Use(&query)
+		a.NilError(err)
+
+		_, err = Builder(stmt)
+		a.Assert(errors.Is(err, ErrMissingParam))
+	}
+}
+
+func TestBuilderModify(t *testing.T) {
+	a := assert.New(t)
+	stmt, err := Parse("SELECT id FROM vacancies WHERE MATCH('golang') LIMIT 10")
+	a.NilError(err)
+
+	sb, err := stmt.(*SelectStmt).SelectBuilder()
+	a.NilError(err)
+
+	sb.Where(sb.In("city_id", 1, 2))
+	sb.Offset(20)
+
+	sql, args := sb.Build()
+	a.Equal(sql, "SELECT id FROM vacancies WHERE MATCH(?) AND city_id IN (?, ?) LIMIT 20,10")
+	a.Equal(args, []interface{}{"golang", 1, 2})
+	a.NilError(sb.Validate())
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	sphinxql "github.com/superjobru/go-sphinxql"
+)
+
+// searchdString is the rule of a string literal in the lexer of searchd (sphinxql.l).
+// Like in flex, "." doesn't match a new line.
+var searchdString = regexp.MustCompile(`^'([^'\\]|\\.|\\\\)*'$`)
+
+// FuzzQuoteRoundTrip proves that an interpolated string cannot break out of its literal.
+// The query is parsed back and must have exactly the same structure and values.
+func FuzzQuoteRoundTrip(f *testing.F) {
+	seeds := []string{
+		"",
+		"plain text",
+		"it's",
+		`\`,
+		`\'`,
+		`\\'`,
+		`'; DROP TABLE idx; --`,
+		`' OR 1=1 --`,
+		`\' OR 1=1 /*`,
+		`') OR MATCH('x`,
+		"\x00\x1a\b\n\r\t",
+		`"quoted" @title (a|b) -c !d ~e`,
+		`a* ^start end$ near/3 <<`,
+		"? $0 ${x} @var",
+		"中文 ünïcode",
+		"\xff\xfe'",
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		sb := sphinxql.NewSelectBuilder()
+		sb.Select("id").From("idx")
+		sb.Where(sb.MatchText(s), sb.Equal("name", s))
+
+		sql, args := sb.Build()
+		query, err := sphinxql.SphinxSearch.Interpolate(sql, args)
+
+		if err != nil {
+			t.Fatalf("interpolate %q: %v", s, err)
+		}
+
+		// The literals are checked by the rule of searchd as well, so that the check
+		// doesn't rely on the lexer of this package only.
+		for _, lit := range []string{sphinxql.SphinxSearch.QuoteString(s), sphinxql.SphinxSearch.QuoteString(sphinxql.EscapeMatch(s))} {
+			if !searchdString.MatchString(lit) {
+				t.Fatalf("searchd doesn't read %q as a single string literal", lit)
+			}
+		}
+
+		stmt, err := Parse(query)
+
+		if err != nil {
+			t.Fatalf("parse %q: %v", query, err)
+		}
+
+		// The searchd reads "\0" as a space.
+		expected := strings.ReplaceAll(s, "\x00", " ")
+		sel, ok := stmt.(*SelectStmt)
+
+		if !ok || len(sel.Fields) != 1 || len(sel.From) != 1 || sel.Limit != -1 || len(sel.Options) != 0 {
+			t.Fatalf("unexpected statement %q", query)
+		}
+
+		and, ok := sel.Where.(*BinaryExpr)
+
+		if !ok || and.Op != "AND" {
+			t.Fatalf("unexpected WHERE in %q", query)
+		}
+
+		match, ok := and.Left.(*FuncCall)
+
+		if !ok || match.Name != "MATCH" || len(match.Args) != 1 {
+			t.Fatalf("unexpected MATCH in %q", query)
+		}
+
+		text, ok := match.Args[0].(*StringLit)
+
+		if !ok || unescapeMatch(text.Value) != expected {
+			t.Fatalf("MATCH text of %q is not %q", query, expected)
+		}
+
+		eq, ok := and.Right.(*BinaryExpr)
+
+		if !ok || eq.Op != "=" {
+			t.Fatalf("unexpected comparison in %q", query)
+		}
+
+		if lit, ok := eq.Right.(*StringLit); !ok || lit.Value != expected {
+			t.Fatalf("value of %q is not %q", query, expected)
+		}
+	})
+}
+
+// unescapeMatch reverts `sphinxql.EscapeMatch` in the same way as the full-text query parser.
+// It fails the round trip if an operator is left unescaped.
+func unescapeMatch(s string) string {
+	buf := &strings.Builder{}
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+			buf.WriteByte(s[i])
+		case strings.IndexByte(`\()|-!@~"&/^$=<*'`, c) >= 0:
+			// An unescaped operator.
+			buf.WriteString("\x00op")
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	return buf.String()
+}
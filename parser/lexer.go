@@ -0,0 +1,261 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenParam
+	tokenOp
+)
+
+// token is a lexical token of SphinxQL.
+type token struct {
+	kind tokenKind
+	pos  int
+
+	// text is the token as is in the source, e.g. "`name`" or "'it\'s'".
+	text string
+
+	// value is the unquoted value of a string or a quoted identifier.
+	value string
+}
+
+// is returns true if t is an operator or a keyword equal to s.
+// Keywords are compared case-insensitively.
+func (t token) is(s string) bool {
+	switch t.kind {
+	case tokenOp:
+		return t.text == s
+	case tokenIdent:
+		return strings.EqualFold(t.text, s)
+	}
+
+	return false
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of query"
+	}
+
+	return fmt.Sprintf("%q", t.text)
+}
+
+// operators are sorted by length, so the longest one is matched first.
+var operators = []string{
+	"<=", ">=", "<>", "!=", "||", "&&",
+	"=", "<", ">", "+", "-", "*", "/", "%", "&", "|", "~", "!",
+	"(", ")", ",", ";", ".", "{", "}", "[", "]",
+}
+
+// tokenize splits query into tokens. Comments are skipped.
+// The last token is always tokenEOF.
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(query); {
+		c := query[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '#' || (c == '-' && strings.HasPrefix(query[i:], "-- ")):
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+
+			if end < 0 {
+				return nil, syntaxError(i, "unterminated comment")
+			}
+
+			i += end + 4
+
+		case c == '\'' || c == '"':
+			value, end, ok := unquote(query, i)
+
+			if !ok {
+				return nil, syntaxError(i, "unterminated string")
+			}
+
+			tokens = append(tokens, token{kind: tokenString, pos: i, text: query[i:end], value: value})
+			i = end
+
+		case c == '`':
+			end := strings.IndexByte(query[i+1:], '`')
+
+			if end < 0 {
+				return nil, syntaxError(i, "unterminated quoted identifier")
+			}
+
+			end += i + 2
+			tokens = append(tokens, token{kind: tokenQuotedIdent, pos: i, text: query[i:end], value: query[i+1 : end-1]})
+			i = end
+
+		case c == '?':
+			tokens = append(tokens, token{kind: tokenParam, pos: i, text: "?"})
+			i++
+
+		case isDigit(c) || (c == '.' && i+1 < len(query) && isDigit(query[i+1])):
+			end := scanNumber(query, i)
+			tokens = append(tokens, token{kind: tokenNumber, pos: i, text: query[i:end]})
+			i = end
+
+		case isIdentStart(c):
+			end := i + 1
+
+			for end < len(query) && isIdentPart(query[end]) {
+				end++
+			}
+
+			tokens = append(tokens, token{kind: tokenIdent, pos: i, text: query[i:end]})
+			i = end
+
+		default:
+			op := ""
+
+			for _, o := range operators {
+				if strings.HasPrefix(query[i:], o) {
+					op = o
+					break
+				}
+			}
+
+			if op == "" {
+				return nil, syntaxError(i, fmt.Sprintf("unexpected character %q", c))
+			}
+
+			tokens = append(tokens, token{kind: tokenOp, pos: i, text: op})
+			i += len(op)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(query)})
+	return tokens, nil
+}
+
+// unquote reads a string quoted by query[start] and returns its value and the index after the closing quote.
+func unquote(query string, start int) (value string, end int, ok bool) {
+	quote := query[start]
+	buf := &strings.Builder{}
+
+	for i := start + 1; i < len(query); i++ {
+		c := query[i]
+
+		switch c {
+		case '\\':
+			i++
+
+			if i == len(query) {
+				return
+			}
+
+			switch query[i] {
+			case 'b':
+				buf.WriteByte('\b')
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case '0':
+				// The searchd reads "\0" as a space.
+				buf.WriteByte(' ')
+			default:
+				buf.WriteByte(query[i])
+			}
+
+		case quote:
+			// A doubled quote is an escaped quote.
+			if i+1 < len(query) && query[i+1] == quote {
+				buf.WriteByte(quote)
+				i++
+				continue
+			}
+
+			return buf.String(), i + 1, true
+
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	return
+}
+
+func scanNumber(query string, start int) int {
+	i := start
+
+	if strings.HasPrefix(query[i:], "0x") || strings.HasPrefix(query[i:], "0X") {
+		i += 2
+
+		for i < len(query) && isHexDigit(query[i]) {
+			i++
+		}
+
+		return i
+	}
+
+	for i < len(query) && isDigit(query[i]) {
+		i++
+	}
+
+	if i < len(query) && query[i] == '.' {
+		i++
+
+		for i < len(query) && isDigit(query[i]) {
+			i++
+		}
+	}
+
+	if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+		j := i + 1
+
+		if j < len(query) && (query[j] == '+' || query[j] == '-') {
+			j++
+		}
+
+		if j < len(query) && isDigit(query[j]) {
+			i = j
+
+			for i < len(query) && isDigit(query[i]) {
+				i++
+			}
+		}
+	}
+
+	return i
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '@' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
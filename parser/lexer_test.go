@@ -0,0 +1,90 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestTokenize(t *testing.T) {
+	a := assert.New(t)
+	cases := map[string][]token{
+		"SELECT @count": {
+			{kind: tokenIdent, pos: 0, text: "SELECT"},
+			{kind: tokenIdent, pos: 7, text: "@count"},
+			{kind: tokenEOF, pos: 13},
+		},
+		"a<=1.5e3,`b`": {
+			{kind: tokenIdent, pos: 0, text: "a"},
+			{kind: tokenOp, pos: 1, text: "<="},
+			{kind: tokenNumber, pos: 3, text: "1.5e3"},
+			{kind: tokenOp, pos: 8, text: ","},
+			{kind: tokenQuotedIdent, pos: 9, text: "`b`", value: "b"},
+			{kind: tokenEOF, pos: 12},
+		},
+		`'it\'s' "a""b" ?`: {
+			{kind: tokenString, pos: 0, text: `'it\'s'`, value: "it's"},
+			{kind: tokenString, pos: 8, text: `"a""b"`, value: `a"b`},
+			{kind: tokenParam, pos: 15, text: "?"},
+			{kind: tokenEOF, pos: 16},
+		},
+		"/* hint */ 0x1F -- comment\n# comment\n.5": {
+			{kind: tokenNumber, pos: 11, text: "0x1F"},
+			{kind: tokenNumber, pos: 37, text: ".5"},
+			{kind: tokenEOF, pos: 39},
+		},
+	}
+
+	for query, expected := range cases {
+		tokens, err := tokenize(query)
+		a.Use(&query)
+		a.NilError(err)
+		a.Equal(tokens, expected)
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	a := assert.New(t)
+	cases := []string{
+		"SELECT 'abc",
+		"SELECT `abc",
+		"SELECT /* abc",
+		"SELECT $a",
+	}
+
+	for _, query := range cases {
+		_, err := tokenize(query)
+		a.Use(&query)
+		a.Assert(errors.Is(err, ErrSyntax))
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	a := assert.New(t)
+
+	// Values read by SqlUnescape of searchd.
+	cases := map[string]string{
+		`''`:             "",
+		`'it\'s'`:        "it's",
+		`'C:\\dir'`:      `C:\dir`,
+		`'\b\n\r\t'`:     "\b\n\r\t",
+		`'a\0b'`:         "a b",
+		`'\Z\x\"\%'`:     `Zx"%`,
+		"'\x1a\x00\xff'": "\x1a\x00\xff",
+		`'"'`:            `"`,
+		`'\\\''`:         `\'`,
+	}
+
+	for literal, expected := range cases {
+		value, end, ok := unquote(literal+" rest", 0)
+		a.Use(&literal)
+		a.Assert(ok)
+		a.Equal(value, expected)
+		a.Equal(end, len(literal))
+	}
+}
@@ -0,0 +1,1002 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+// Package parser parses SphinxQL statements into an AST,
+// which can be inspected, modified and converted to builders of package sphinxql.
+//
+// Supported statements are SELECT, INSERT, REPLACE, UPDATE, DELETE and CALL.
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrSyntax means that a query cannot be parsed.
+	ErrSyntax = errors.New("go-sphinxql: syntax error")
+
+	// ErrMissingParam means that there is no value for a placeholder "?" when converting a statement to a builder.
+	ErrMissingParam = errors.New("go-sphinxql: missing value for placeholder")
+
+	// ErrUnsupportedStatement means that a statement cannot be converted to a builder.
+	ErrUnsupportedStatement = errors.New("go-sphinxql: unsupported statement")
+)
+
+// SyntaxError is an error of parsing. It wraps ErrSyntax.
+type SyntaxError struct {
+	// Pos is the byte offset of the error in the query.
+	Pos int
+	Msg string
+}
+
+func syntaxError(pos int, msg string) error {
+	return &SyntaxError{Pos: pos, Msg: msg}
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%v at position %d: %s", ErrSyntax, e.Pos, e.Msg)
+}
+
+// Unwrap returns ErrSyntax.
+func (e *SyntaxError) Unwrap() error {
+	return ErrSyntax
+}
+
+// reserved keywords cannot be used as aliases without AS.
+var reserved = map[string]bool{
+	"AND": true, "AS": true, "ASC": true, "BETWEEN": true, "BY": true, "DESC": true,
+	"DIV": true, "FACET": true, "FROM": true, "GROUP": true, "HAVING": true, "IN": true,
+	"IS": true, "LIKE": true, "LIMIT": true, "MOD": true, "NOT": true, "NULL": true,
+	"OFFSET": true, "OPTION": true, "OR": true, "ORDER": true, "SET": true, "VALUES": true,
+	"WHERE": true, "WITHIN": true,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	params int
+}
+
+// Parse parses a single SphinxQL statement. A trailing semicolon is allowed.
+func Parse(query string) (Statement, error) {
+	tokens, err := tokenize(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	stmt, err := p.statement()
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.accept(";")
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.unexpected(t)
+	}
+
+	return stmt, nil
+}
+
+// ParseExpr parses an expression, e.g. a condition of WHERE.
+func ParseExpr(expr string) (Expr, error) {
+	tokens, err := tokenize(expr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	e, err := p.expr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.unexpected(t)
+	}
+
+	return e, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekN(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+
+	return p.tokens[p.pos+n]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+// accept consumes the next token if it's s.
+func (p *parser) accept(s string) bool {
+	if p.peek().is(s) {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+// acceptAll consumes the next tokens if they are all the keywords in s.
+func (p *parser) acceptAll(s ...string) bool {
+	for i, k := range s {
+		if !p.peekN(i).is(k) {
+			return false
+		}
+	}
+
+	p.pos += len(s)
+	return true
+}
+
+func (p *parser) expect(s ...string) error {
+	for _, k := range s {
+		if !p.accept(k) {
+			return p.expected(k)
+		}
+	}
+
+	return nil
+}
+
+func (p *parser) expected(what string) error {
+	t := p.peek()
+	return syntaxError(t.pos, fmt.Sprintf("expected %s, got %v", what, t))
+}
+
+func (p *parser) unexpected(t token) error {
+	return syntaxError(t.pos, fmt.Sprintf("unexpected %v", t))
+}
+
+func (p *parser) statement() (Statement, error) {
+	t := p.peek()
+
+	switch {
+	case t.is("SELECT"):
+		return p.selectStmt()
+	case t.is("INSERT"), t.is("REPLACE"):
+		return p.insertStmt()
+	case t.is("UPDATE"):
+		return p.updateStmt()
+	case t.is("DELETE"):
+		return p.deleteStmt()
+	case t.is("CALL"):
+		return p.callStmt()
+	}
+
+	return nil, syntaxError(t.pos, fmt.Sprintf("unsupported statement %v", t))
+}
+
+func (p *parser) selectStmt() (*SelectStmt, error) {
+	p.next()
+	stmt := &SelectStmt{
+		Limit:  -1,
+		Offset: -1,
+	}
+
+	for {
+		f, err := p.selectField()
+
+		if err != nil {
+			return nil, err
+		}
+
+		stmt.Fields = append(stmt.Fields, f)
+
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+
+	tables, err := p.names()
+
+	if err != nil {
+		return nil, err
+	}
+
+	stmt.From = tables
+
+	if p.accept("WHERE") {
+		if stmt.Where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.accept("GROUP") {
+		if t := p.peek(); t.kind == tokenNumber {
+			p.next()
+
+			if stmt.GroupN, err = strconv.Atoi(t.text); err != nil {
+				return nil, syntaxError(t.pos, "invalid number of GROUP N BY")
+			}
+		}
+
+		if err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+
+		if stmt.GroupBy, err = p.exprList(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptAll("WITHIN", "GROUP", "ORDER", "BY") {
+		if stmt.WithinGroupOrderBy, err = p.orderItems(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Like searchd, HAVING is accepted only after WITHIN GROUP ORDER BY.
+	if t := p.peek(); t.is("HAVING") {
+		if stmt.GroupBy == nil {
+			return nil, syntaxError(t.pos, "HAVING without GROUP BY")
+		}
+
+		p.next()
+
+		if stmt.Having, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptAll("ORDER", "BY") {
+		if stmt.OrderBy, err = p.orderItems(); err != nil {
+			return nil, err
+		}
+	}
+
+	if t := p.peek(); p.accept("LIMIT") {
+		stmt.LimitPos = t.pos
+		n, err := p.int()
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case p.accept(","):
+			stmt.Offset = n
+
+			if stmt.Limit, err = p.int(); err != nil {
+				return nil, err
+			}
+		case p.accept("OFFSET"):
+			stmt.Limit = n
+
+			if stmt.Offset, err = p.int(); err != nil {
+				return nil, err
+			}
+		default:
+			stmt.Limit = n
+		}
+	}
+
+	if p.accept("OPTION") {
+		if stmt.Options, err = p.options(); err != nil {
+			return nil, err
+		}
+	}
+
+	if t := p.peek(); t.is("FACET") {
+		return nil, syntaxError(t.pos, "FACET is not supported")
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) selectField() (SelectField, error) {
+	var f SelectField
+
+	if t := p.peek(); p.accept("*") {
+		f.Expr = &Star{node: node{t.pos}}
+		return f, nil
+	}
+
+	e, err := p.expr()
+
+	if err != nil {
+		return f, err
+	}
+
+	f.Expr = e
+
+	if p.accept("AS") {
+		t := p.next()
+
+		if t.kind != tokenIdent && t.kind != tokenQuotedIdent {
+			return f, syntaxError(t.pos, fmt.Sprintf("expected alias, got %v", t))
+		}
+
+		f.Alias = t.text
+	} else if t := p.peek(); (t.kind == tokenIdent && !reserved[strings.ToUpper(t.text)]) || t.kind == tokenQuotedIdent {
+		p.next()
+		f.Alias = t.text
+	}
+
+	return f, nil
+}
+
+func (p *parser) orderItems() ([]OrderItem, error) {
+	var items []OrderItem
+
+	for {
+		e, err := p.expr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		item := OrderItem{Expr: e}
+
+		if t := p.peek(); t.is("ASC") || t.is("DESC") {
+			p.next()
+			item.Dir = strings.ToUpper(t.text)
+		}
+
+		items = append(items, item)
+
+		if !p.accept(",") {
+			return items, nil
+		}
+	}
+}
+
+func (p *parser) options() ([]Option, error) {
+	var options []Option
+
+	for {
+		name, err := p.name()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+
+		value, err := p.expr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, Option{Name: name, Value: value})
+
+		if !p.accept(",") {
+			return options, nil
+		}
+	}
+}
+
+func (p *parser) insertStmt() (*InsertStmt, error) {
+	stmt := &InsertStmt{
+		Replace: p.next().is("REPLACE"),
+	}
+
+	if err := p.expect("INTO"); err != nil {
+		return nil, err
+	}
+
+	table, err := p.name()
+
+	if err != nil {
+		return nil, err
+	}
+
+	stmt.Table = table
+
+	if p.accept("(") {
+		if stmt.Columns, err = p.names(); err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.expect("VALUES"); err != nil {
+		return nil, err
+	}
+
+	for {
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+
+		row, err := p.exprList()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+
+		stmt.Rows = append(stmt.Rows, row)
+
+		if !p.accept(",") {
+			return stmt, nil
+		}
+	}
+}
+
+func (p *parser) updateStmt() (*UpdateStmt, error) {
+	p.next()
+	table, err := p.name()
+
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &UpdateStmt{Table: table}
+
+	if err := p.expect("SET"); err != nil {
+		return nil, err
+	}
+
+	for {
+		col, err := p.name()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+
+		value, err := p.expr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		stmt.Set = append(stmt.Set, Assignment{Column: col, Value: value})
+
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if p.accept("WHERE") {
+		if stmt.Where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.accept("OPTION") {
+		if stmt.Options, err = p.options(); err != nil {
+			return nil, err
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) deleteStmt() (*DeleteStmt, error) {
+	p.next()
+
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+
+	table, err := p.name()
+
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &DeleteStmt{Table: table}
+
+	if p.accept("WHERE") {
+		if stmt.Where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) callStmt() (*CallStmt, error) {
+	p.next()
+	name, err := p.name()
+
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &CallStmt{Name: name}
+
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	for !p.peek().is(")") {
+		arg, err := p.expr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Options of CALL are passed as "value AS name".
+		if t := p.peek(); p.accept("AS") {
+			name, err := p.name()
+
+			if err != nil {
+				return nil, err
+			}
+
+			arg = &AliasExpr{node: node{t.pos}, Expr: arg, Alias: name}
+		}
+
+		stmt.Args = append(stmt.Args, arg)
+
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	return stmt, nil
+}
+
+// name parses an identifier, which may be qualified by dots, e.g. "j.field".
+func (p *parser) name() (string, error) {
+	t := p.next()
+
+	if t.kind != tokenIdent && t.kind != tokenQuotedIdent {
+		return "", syntaxError(t.pos, fmt.Sprintf("expected name, got %v", t))
+	}
+
+	name := t.text
+
+	for p.peek().is(".") {
+		if n := p.peekN(1); n.kind == tokenIdent || n.kind == tokenQuotedIdent {
+			p.pos += 2
+			name += "." + n.text
+			continue
+		}
+
+		break
+	}
+
+	return name, nil
+}
+
+func (p *parser) names() ([]string, error) {
+	var names []string
+
+	for {
+		name, err := p.name()
+
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+
+		if !p.accept(",") {
+			return names, nil
+		}
+	}
+}
+
+func (p *parser) int() (int, error) {
+	t := p.next()
+
+	if t.kind != tokenNumber {
+		return 0, syntaxError(t.pos, fmt.Sprintf("expected number, got %v", t))
+	}
+
+	n, err := strconv.Atoi(t.text)
+
+	if err != nil || n < 0 {
+		return 0, syntaxError(t.pos, fmt.Sprintf("invalid number %v", t))
+	}
+
+	return n, nil
+}
+
+func (p *parser) exprList() ([]Expr, error) {
+	var exprs []Expr
+
+	for {
+		e, err := p.expr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, e)
+
+		if !p.accept(",") {
+			return exprs, nil
+		}
+	}
+}
+
+func (p *parser) expr() (Expr, error) {
+	return p.binary(precOr)
+}
+
+// binary parses binary operators with precedence prec or higher.
+func (p *parser) binary(prec int) (Expr, error) {
+	switch prec {
+	case precNot:
+		if t := p.peek(); p.accept("NOT") {
+			e, err := p.binary(precNot)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return &UnaryExpr{node: node{t.pos}, Op: "NOT", Expr: e}, nil
+		}
+
+		return p.binary(precCompare)
+	case precCompare:
+		return p.comparison()
+	case precUnary:
+		return p.unary()
+	}
+
+	left, err := p.binary(prec + 1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		op := strings.ToUpper(t.text)
+
+		if (t.kind != tokenOp && t.kind != tokenIdent) || binaryPrecs[op] != prec {
+			return left, nil
+		}
+
+		p.next()
+		right, err := p.binary(prec + 1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		left = &BinaryExpr{node: node{t.pos}, Op: op, Left: left, Right: right}
+	}
+}
+
+func (p *parser) comparison() (Expr, error) {
+	left, err := p.binary(precBitOr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		not := false
+
+		if t.is("NOT") {
+			if n := p.peekN(1); n.is("IN") || n.is("BETWEEN") || n.is("LIKE") {
+				p.next()
+				t = n
+				not = true
+			}
+		}
+
+		switch {
+		case t.is("IN"):
+			p.next()
+
+			if err := p.expect("("); err != nil {
+				return nil, err
+			}
+
+			values, err := p.exprList()
+
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+
+			left = &InExpr{node: node{t.pos}, Expr: left, Not: not, Values: values}
+		case t.is("BETWEEN"):
+			p.next()
+			lower, err := p.binary(precBitOr)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expect("AND"); err != nil {
+				return nil, err
+			}
+
+			upper, err := p.binary(precBitOr)
+
+			if err != nil {
+				return nil, err
+			}
+
+			left = &BetweenExpr{node: node{t.pos}, Expr: left, Not: not, Lower: lower, Upper: upper}
+		case t.is("LIKE"):
+			p.next()
+			right, err := p.binary(precBitOr)
+
+			if err != nil {
+				return nil, err
+			}
+
+			op := "LIKE"
+
+			if not {
+				op = "NOT LIKE"
+			}
+
+			left = &BinaryExpr{node: node{t.pos}, Op: op, Left: left, Right: right}
+		case t.is("IS"):
+			p.next()
+			e := &IsNullExpr{node: node{t.pos}, Expr: left, Not: p.accept("NOT")}
+
+			if err := p.expect("NULL"); err != nil {
+				return nil, err
+			}
+
+			left = e
+		case t.kind == tokenOp && binaryPrecs[t.text] == precCompare:
+			p.next()
+			right, err := p.binary(precBitOr)
+
+			if err != nil {
+				return nil, err
+			}
+
+			left = &BinaryExpr{node: node{t.pos}, Op: t.text, Left: left, Right: right}
+		default:
+			return left, nil
+		}
+	}
+}
+
+func (p *parser) unary() (Expr, error) {
+	t := p.peek()
+
+	if t.is("-") || t.is("!") || t.is("~") {
+		p.next()
+		e, err := p.unary()
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Fold negative numbers.
+		if n, ok := e.(*NumberLit); ok && t.text == "-" {
+			switch v := n.Value.(type) {
+			case int64:
+				return &NumberLit{node: node{t.pos}, Text: "-" + n.Text, Value: -v}, nil
+			case float64:
+				return &NumberLit{node: node{t.pos}, Text: "-" + n.Text, Value: -v}, nil
+			}
+		}
+
+		return &UnaryExpr{node: node{t.pos}, Op: t.text, Expr: e}, nil
+	}
+
+	return p.primary()
+}
+
+func (p *parser) primary() (Expr, error) {
+	t := p.peek()
+
+	switch t.kind {
+	case tokenString:
+		p.next()
+		return &StringLit{node: node{t.pos}, Value: t.value}, nil
+	case tokenNumber:
+		p.next()
+		return parseNumber(t)
+	case tokenParam:
+		p.next()
+		e := &Param{node: node{t.pos}, Index: p.params}
+		p.params++
+		return e, nil
+	case tokenIdent, tokenQuotedIdent:
+		if t.kind == tokenIdent && p.peekN(1).is("(") {
+			return p.funcCall()
+		}
+
+		if t.is("NULL") {
+			p.next()
+			return &NullLit{node: node{t.pos}}, nil
+		}
+
+		if t.kind == tokenIdent && reserved[strings.ToUpper(t.text)] {
+			return nil, p.unexpected(t)
+		}
+
+		name, err := p.name()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &Ident{node: node{t.pos}, Name: name}, nil
+	}
+
+	switch {
+	case t.is("("):
+		p.next()
+
+		// An empty MVA value.
+		if p.accept(")") {
+			return &TupleExpr{node: node{t.pos}}, nil
+		}
+
+		items, err := p.exprList()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+
+		if len(items) == 1 {
+			return &ParenExpr{node: node{t.pos}, Expr: items[0]}, nil
+		}
+
+		return &TupleExpr{node: node{t.pos}, Items: items}, nil
+	case t.is("{"):
+		p.next()
+		e := &ObjectExpr{node: node{t.pos}}
+
+		for !p.peek().is("}") {
+			// Keys of objects may be keywords, e.g. "{in=deg}".
+			kt := p.peek()
+			key, err := p.name()
+
+			if err != nil {
+				return nil, err
+			}
+
+			eq := p.peek()
+
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+
+			value, err := p.expr()
+
+			if err != nil {
+				return nil, err
+			}
+
+			e.Items = append(e.Items, &BinaryExpr{
+				node:  node{eq.pos},
+				Op:    "=",
+				Left:  &Ident{node: node{kt.pos}, Name: key},
+				Right: value,
+			})
+
+			if !p.accept(",") {
+				break
+			}
+		}
+
+		if err := p.expect("}"); err != nil {
+			return nil, err
+		}
+
+		return e, nil
+	}
+
+	return nil, p.unexpected(t)
+}
+
+func (p *parser) funcCall() (Expr, error) {
+	t := p.next()
+	p.next()
+	call := &FuncCall{node: node{t.pos}, Name: t.text}
+
+	if p.accept(")") {
+		return call, nil
+	}
+
+	call.Distinct = p.accept("DISTINCT")
+
+	for {
+		var arg Expr
+
+		if t := p.peek(); p.accept("*") {
+			arg = &Star{node: node{t.pos}}
+		} else {
+			e, err := p.expr()
+
+			if err != nil {
+				return nil, err
+			}
+
+			arg = e
+		}
+
+		call.Args = append(call.Args, arg)
+
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	return call, nil
+}
+
+func parseNumber(t token) (Expr, error) {
+	text := t.text
+
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X") {
+		v, err := strconv.ParseUint(text[2:], 16, 64)
+
+		if err != nil {
+			return nil, syntaxError(t.pos, fmt.Sprintf("invalid number %v", t))
+		}
+
+		return &NumberLit{node: node{t.pos}, Text: text, Value: v}, nil
+	}
+
+	if !strings.ContainsAny(text, ".eE") {
+		if v, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return &NumberLit{node: node{t.pos}, Text: text, Value: v}, nil
+		}
+
+		if v, err := strconv.ParseUint(text, 10, 64); err == nil {
+			return &NumberLit{node: node{t.pos}, Text: text, Value: v}, nil
+		}
+	}
+
+	v, err := strconv.ParseFloat(text, 64)
+
+	if err != nil {
+		return nil, syntaxError(t.pos, fmt.Sprintf("invalid number %v", t))
+	}
+
+	return &NumberLit{node: node{t.pos}, Text: text, Value: v}, nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func ExampleParse() {
+	stmt, err := Parse("SELECT id FROM vacancies WHERE MATCH('golang') AND city_id = 1 LIMIT 20, 10")
+
+	if err != nil {
+		panic(err)
+	}
+
+	sel := stmt.(*SelectStmt)
+	sel.Where = &BinaryExpr{
+		Op:    "AND",
+		Left:  sel.Where,
+		Right: &InExpr{Expr: &Ident{Name: "company_id"}, Values: []Expr{&Param{}}},
+	}
+	sel.Limit = 50
+
+	fmt.Println(sel)
+
+	sb, err := sel.SelectBuilder(123)
+
+	if err != nil {
+		panic(err)
+	}
+
+	sb.OrderBy(sb.Desc("id"))
+	fmt.Println(sb.Build())
+
+	// Output:
+	// SELECT id FROM vacancies WHERE MATCH('golang') AND city_id = 1 AND company_id IN (?) LIMIT 20,50
+	// SELECT id FROM vacancies WHERE MATCH(?) AND city_id = ? AND company_id IN (?) ORDER BY id DESC LIMIT 20,50 [golang 1 123]
+}
+
+func TestParse(t *testing.T) {
+	a := assert.New(t)
+	cases := map[string]string{
+		// Normalized queries are kept as is.
+		"SELECT * FROM idx": "",
+		"SELECT id, WEIGHT() AS w FROM idx1, idx2 WHERE MATCH('@title go')":                                                                          "",
+		"SELECT id FROM idx WHERE a = 1 AND (b > 2 OR c <= 3.5) AND NOT d IN (1, 2) AND e NOT BETWEEN -1 AND 1":                                      "",
+		"SELECT COUNT(DISTINCT company_id) AS cnt, GROUPBY() AS g FROM idx GROUP 3 BY company_id WITHIN GROUP ORDER BY WEIGHT() DESC HAVING cnt > 1": "",
+		"SELECT id FROM idx WHERE title LIKE 'go%' AND t IS NOT NULL AND j.x IS NULL ORDER BY a ASC, b DESC, c":                                      "",
+		"SELECT id FROM idx OPTION ranker = expr('sum(lcs)'), field_weights = (title = 10, body = 1), max_matches = 1000":                            "",
+		"SELECT GEODIST(lat, lon, 0.5, 1, {in = deg, out = km}) AS d FROM idx WHERE d < 1000":                                                        "",
+		"SELECT (a + b) * 2 - c / 3 % 4 AS x, a | b & ~c, -x FROM idx WHERE x DIV 2 = 1 OR y MOD 2 = 0":                                              "",
+		"INSERT INTO idx (id, title, tags) VALUES (1, 'it\\'s', (1, 2, 3)), (2, ?, ())":                                                              "",
+		"REPLACE INTO idx VALUES (1, 'x')": "",
+		"UPDATE idx SET price = price * 2, `tags` = (1, 2) WHERE id IN (1, 2) OPTION strict = 1": "",
+		"DELETE FROM idx WHERE id = 1 OR id = 2":                                                 "",
+		"CALL SNIPPETS('text', 'idx', 'query', 5 AS limit)":                                      "",
+
+		// Other queries are normalized.
+		"select id from idx where a=1 and b<>'x' limit 10;":                     "SELECT id FROM idx WHERE a = 1 AND b <> 'x' LIMIT 10",
+		"SELECT id, price p FROM idx LIMIT 10 OFFSET 20":                        "SELECT id, price AS p FROM idx LIMIT 20,10",
+		"SELECT id FROM idx GROUP BY a WITHIN GROUP ORDER BY b desc HAVING c>1": "SELECT id FROM idx GROUP BY a WITHIN GROUP ORDER BY b DESC HAVING c > 1",
+		`SELECT id FROM idx WHERE MATCH("\"go\"") /* comment */`:                `SELECT id FROM idx WHERE MATCH('"go"')`,
+		"SELECT id FROM idx WHERE a NOT LIKE 'x' AND b = 0x10":                  "SELECT id FROM idx WHERE a NOT LIKE 'x' AND b = 0x10",
+		"insert into idx values (1)":                                            "INSERT INTO idx VALUES (1)",
+		"call keywords('a b', 'idx')":                                           "CALL keywords('a b', 'idx')",
+	}
+
+	for query, expected := range cases {
+		if expected == "" {
+			expected = query
+		}
+
+		stmt, err := Parse(query)
+		a.Use(&query, &expected)
+		a.NilError(err)
+		a.Equal(stmt.String(), expected)
+	}
+}
+
+func TestParseExpr(t *testing.T) {
+	a := assert.New(t)
+	e, err := ParseExpr("a = 1 OR b = ? AND NOT c")
+	a.NilError(err)
+	a.Equal(e, &BinaryExpr{
+		node: node{6},
+		Op:   "OR",
+		Left: &BinaryExpr{
+			node:  node{2},
+			Op:    "=",
+			Left:  &Ident{node: node{0}, Name: "a"},
+			Right: &NumberLit{node: node{4}, Text: "1", Value: int64(1)},
+		},
+		Right: &BinaryExpr{
+			node: node{15},
+			Op:   "AND",
+			Left: &BinaryExpr{
+				node:  node{11},
+				Op:    "=",
+				Left:  &Ident{node: node{9}, Name: "b"},
+				Right: &Param{node: node{13}, Index: 0},
+			},
+			Right: &UnaryExpr{node: node{19}, Op: "NOT", Expr: &Ident{node: node{23}, Name: "c"}},
+		},
+	})
+
+	e, err = ParseExpr("18446744073709551615 + 1.5")
+	a.NilError(err)
+	a.Equal(e.(*BinaryExpr).Left.(*NumberLit).Value, uint64(18446744073709551615))
+	a.Equal(e.(*BinaryExpr).Right.(*NumberLit).Value, 1.5)
+	a.Equal(e.(*BinaryExpr).Right.Pos(), 23)
+
+	stmt, err := Parse("SELECT id FROM idx LIMIT 10")
+	a.NilError(err)
+	a.Equal(stmt.(*SelectStmt).LimitPos, 19)
+
+	// Parentheses are added for a tree built manually.
+	e = &BinaryExpr{
+		Op:    "AND",
+		Left:  &BinaryExpr{Op: "OR", Left: &Ident{Name: "a"}, Right: &Ident{Name: "b"}},
+		Right: &UnaryExpr{Op: "NOT", Expr: &BinaryExpr{Op: "OR", Left: &Ident{Name: "c"}, Right: &Ident{Name: "d"}}},
+	}
+	a.Equal(e.String(), "(a OR b) AND NOT (c OR d)")
+
+	_, err = ParseExpr("a = 1)")
+	a.Assert(errors.Is(err, ErrSyntax))
+}
+
+func TestParseErrors(t *testing.T) {
+	a := assert.New(t)
+	cases := []string{
+		"",
+		"SHOW TABLES",
+		"SELECT",
+		"SELECT id",
+		"SELECT id FROM",
+		"SELECT id FROM idx WHERE",
+		"SELECT id FROM idx WHERE a IN 1",
+		"SELECT id FROM idx WHERE a BETWEEN 1",
+		"SELECT id FROM idx WHERE a IS 1",
+		"SELECT id FROM idx WHERE AND",
+		"SELECT id FROM idx HAVING a > 1",
+		"SELECT id FROM idx GROUP BY a HAVING c > 1 WITHIN GROUP ORDER BY b DESC",
+		"SELECT id FROM idx LIMIT a",
+		"SELECT id FROM idx LIMIT -1",
+		"SELECT id FROM idx OPTION a",
+		"SELECT id FROM idx FACET a",
+		"SELECT id AS 1 FROM idx",
+		"SELECT id FROM idx; SELECT 1",
+		"SELECT f(a, FROM idx",
+		"SELECT {a} FROM idx",
+		"INSERT idx VALUES (1)",
+		"INSERT INTO idx (id VALUES (1)",
+		"INSERT INTO idx (id) (1)",
+		"INSERT INTO idx VALUES 1",
+		"INSERT INTO idx VALUES (1",
+		"UPDATE idx id = 1",
+		"UPDATE idx SET id 1",
+		"DELETE idx",
+		"CALL f",
+		"CALL f(1 AS)",
+	}
+
+	for _, query := range cases {
+		_, err := Parse(query)
+		a.Use(&query)
+		a.Assert(errors.Is(err, ErrSyntax))
+	}
+}
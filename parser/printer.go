@@ -0,0 +1,475 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	sphinxql "github.com/superjobru/go-sphinxql"
+)
+
+// Precedences of operators from the lowest to the highest.
+const (
+	precOr = iota + 1
+	precAnd
+	precNot
+	precCompare
+	precBitOr
+	precBitAnd
+	precAdd
+	precMul
+	precUnary
+	precPrimary
+)
+
+var binaryPrecs = map[string]int{
+	"OR":       precOr,
+	"||":       precOr,
+	"AND":      precAnd,
+	"&&":       precAnd,
+	"=":        precCompare,
+	"<>":       precCompare,
+	"!=":       precCompare,
+	"<":        precCompare,
+	"<=":       precCompare,
+	">":        precCompare,
+	">=":       precCompare,
+	"LIKE":     precCompare,
+	"NOT LIKE": precCompare,
+	"|":        precBitOr,
+	"&":        precBitAnd,
+	"+":        precAdd,
+	"-":        precAdd,
+	"*":        precMul,
+	"/":        precMul,
+	"%":        precMul,
+	"DIV":      precMul,
+	"MOD":      precMul,
+}
+
+func precedence(e Expr) int {
+	switch e := e.(type) {
+	case *BinaryExpr:
+		return binaryPrecs[e.Op]
+	case *UnaryExpr:
+		if e.Op == "NOT" {
+			return precNot
+		}
+
+		return precUnary
+	case *InExpr, *BetweenExpr, *IsNullExpr:
+		return precCompare
+	}
+
+	return precPrimary
+}
+
+// printer writes nodes as SphinxQL.
+//
+// If vars is set, the output is in builder's format: `$` is escaped and
+// values are added by vars, which is usually `Cond#Var` of a builder.
+type printer struct {
+	buf    strings.Builder
+	vars   func(value interface{}) string
+	inline bool
+	params []interface{}
+	err    error
+}
+
+// raw writes a part of SQL which is not a value.
+func (p *printer) raw(s string) {
+	if p.vars != nil {
+		s = sphinxql.Escape(s)
+	}
+
+	p.buf.WriteString(s)
+}
+
+// value writes a literal value v, whose SQL representation is text.
+func (p *printer) value(v interface{}, text string) {
+	if p.vars == nil || p.inline {
+		p.raw(text)
+		return
+	}
+
+	p.buf.WriteString(p.vars(v))
+}
+
+func (p *printer) expr(e Expr) {
+	switch e := e.(type) {
+	case nil:
+		// Nothing.
+	case *Ident:
+		p.raw(e.Name)
+	case *StringLit:
+		p.value(e.Value, sphinxql.SphinxSearch.QuoteString(e.Value))
+	case *NumberLit:
+		p.value(e.Value, e.Text)
+	case *NullLit:
+		p.raw("NULL")
+	case *Star:
+		p.raw("*")
+	case *Param:
+		p.param(e)
+	case *UnaryExpr:
+		p.raw(e.Op)
+
+		if e.Op == "NOT" {
+			p.raw(" ")
+		}
+
+		p.operand(e.Expr, precedence(e), false)
+	case *BinaryExpr:
+		prec := precedence(e)
+		p.operand(e.Left, prec, false)
+		p.raw(" " + e.Op + " ")
+		p.operand(e.Right, prec, true)
+	case *InExpr:
+		p.operand(e.Expr, precCompare, false)
+
+		if e.Not {
+			p.raw(" NOT IN (")
+		} else {
+			p.raw(" IN (")
+		}
+
+		p.list(e.Values)
+		p.raw(")")
+	case *BetweenExpr:
+		p.operand(e.Expr, precCompare, false)
+
+		if e.Not {
+			p.raw(" NOT BETWEEN ")
+		} else {
+			p.raw(" BETWEEN ")
+		}
+
+		p.operand(e.Lower, precCompare, true)
+		p.raw(" AND ")
+		p.operand(e.Upper, precCompare, true)
+	case *IsNullExpr:
+		p.operand(e.Expr, precCompare, false)
+
+		if e.Not {
+			p.raw(" IS NOT NULL")
+		} else {
+			p.raw(" IS NULL")
+		}
+	case *FuncCall:
+		p.raw(e.Name + "(")
+
+		if e.Distinct {
+			p.raw("DISTINCT ")
+		}
+
+		p.list(e.Args)
+		p.raw(")")
+	case *ParenExpr:
+		p.raw("(")
+		p.expr(e.Expr)
+		p.raw(")")
+	case *TupleExpr:
+		p.raw("(")
+		p.list(e.Items)
+		p.raw(")")
+	case *ObjectExpr:
+		// Values of objects are options, which cannot be placeholders.
+		inline := p.inline
+		p.inline = true
+		p.raw("{")
+		p.list(e.Items)
+		p.raw("}")
+		p.inline = inline
+	case *AliasExpr:
+		p.expr(e.Expr)
+		p.raw(" AS " + e.Alias)
+	default:
+		p.raw(fmt.Sprint(e))
+	}
+}
+
+// operand writes e with parentheses if its precedence is lower than prec.
+func (p *printer) operand(e Expr, prec int, right bool) {
+	ep := precedence(e)
+
+	if ep < prec || (right && ep == prec && ep != precOr && ep != precAnd) {
+		p.raw("(")
+		p.expr(e)
+		p.raw(")")
+		return
+	}
+
+	p.expr(e)
+}
+
+func (p *printer) list(exprs []Expr) {
+	for i, e := range exprs {
+		if i > 0 {
+			p.raw(", ")
+		}
+
+		p.expr(e)
+	}
+}
+
+func (p *printer) param(e *Param) {
+	if p.vars == nil {
+		p.raw("?")
+		return
+	}
+
+	if e.Index >= len(p.params) {
+		if p.err == nil {
+			p.err = fmt.Errorf("%w: no value for placeholder #%d", ErrMissingParam, e.Index+1)
+		}
+
+		p.raw("?")
+		return
+	}
+
+	p.buf.WriteString(p.vars(p.params[e.Index]))
+}
+
+func (p *printer) orderItems(items []OrderItem) {
+	for i, item := range items {
+		if i > 0 {
+			p.raw(", ")
+		}
+
+		p.buf.WriteString(p.orderItem(item))
+	}
+}
+
+func (p *printer) orderItem(item OrderItem) string {
+	s := p.sub(item.Expr)
+
+	if item.Dir != "" {
+		s += " " + item.Dir
+	}
+
+	return s
+}
+
+func (p *printer) options(options []Option) {
+	for i, o := range options {
+		if i > 0 {
+			p.raw(", ")
+		}
+
+		p.buf.WriteString(p.option(o))
+	}
+}
+
+// option returns an OPTION item. Values of options are always inline.
+func (p *printer) option(o Option) string {
+	inline := p.inline
+	p.inline = true
+	s := p.sub(o.Value)
+	p.inline = inline
+
+	return p.escape(o.Name) + " = " + s
+}
+
+// sub returns e as a string without writing it to p.
+func (p *printer) sub(e Expr) string {
+	sp := &printer{
+		vars:   p.vars,
+		inline: p.inline,
+		params: p.params,
+	}
+	sp.expr(e)
+
+	if sp.err != nil && p.err == nil {
+		p.err = sp.err
+	}
+
+	return sp.buf.String()
+}
+
+func (p *printer) escape(s string) string {
+	if p.vars != nil {
+		return sphinxql.Escape(s)
+	}
+
+	return s
+}
+
+func exprString(e Expr) string {
+	p := &printer{}
+	p.expr(e)
+	return p.buf.String()
+}
+
+func (e *Ident) String() string       { return exprString(e) }
+func (e *StringLit) String() string   { return exprString(e) }
+func (e *NumberLit) String() string   { return exprString(e) }
+func (e *NullLit) String() string     { return exprString(e) }
+func (e *Param) String() string       { return exprString(e) }
+func (e *Star) String() string        { return exprString(e) }
+func (e *UnaryExpr) String() string   { return exprString(e) }
+func (e *BinaryExpr) String() string  { return exprString(e) }
+func (e *InExpr) String() string      { return exprString(e) }
+func (e *BetweenExpr) String() string { return exprString(e) }
+func (e *IsNullExpr) String() string  { return exprString(e) }
+func (e *FuncCall) String() string    { return exprString(e) }
+func (e *ParenExpr) String() string   { return exprString(e) }
+func (e *TupleExpr) String() string   { return exprString(e) }
+func (e *ObjectExpr) String() string  { return exprString(e) }
+func (e *AliasExpr) String() string   { return exprString(e) }
+
+// String returns the statement as SphinxQL.
+func (s *SelectStmt) String() string {
+	p := &printer{}
+	p.raw("SELECT ")
+
+	for i, f := range s.Fields {
+		if i > 0 {
+			p.raw(", ")
+		}
+
+		p.buf.WriteString(selectField(p, f))
+	}
+
+	p.raw(" FROM " + strings.Join(s.From, ", "))
+
+	if s.Where != nil {
+		p.raw(" WHERE ")
+		p.expr(s.Where)
+	}
+
+	if len(s.GroupBy) > 0 {
+		if s.GroupN > 0 {
+			p.raw(" GROUP " + strconv.Itoa(s.GroupN) + " BY ")
+		} else {
+			p.raw(" GROUP BY ")
+		}
+
+		p.list(s.GroupBy)
+	}
+
+	if len(s.WithinGroupOrderBy) > 0 {
+		p.raw(" WITHIN GROUP ORDER BY ")
+		p.orderItems(s.WithinGroupOrderBy)
+	}
+
+	if len(s.GroupBy) > 0 && s.Having != nil {
+		p.raw(" HAVING ")
+		p.expr(s.Having)
+	}
+
+	if len(s.OrderBy) > 0 {
+		p.raw(" ORDER BY ")
+		p.orderItems(s.OrderBy)
+	}
+
+	if s.Limit >= 0 {
+		p.raw(" LIMIT ")
+
+		if s.Offset >= 0 {
+			p.raw(strconv.Itoa(s.Offset) + ",")
+		}
+
+		p.raw(strconv.Itoa(s.Limit))
+	}
+
+	if len(s.Options) > 0 {
+		p.raw(" OPTION ")
+		p.options(s.Options)
+	}
+
+	return p.buf.String()
+}
+
+func selectField(p *printer, f SelectField) string {
+	s := p.sub(f.Expr)
+
+	if f.Alias != "" {
+		s += " AS " + p.escape(f.Alias)
+	}
+
+	return s
+}
+
+// String returns the statement as SphinxQL.
+func (s *InsertStmt) String() string {
+	p := &printer{}
+
+	if s.Replace {
+		p.raw("REPLACE")
+	} else {
+		p.raw("INSERT")
+	}
+
+	p.raw(" INTO " + s.Table)
+
+	if len(s.Columns) > 0 {
+		p.raw(" (" + strings.Join(s.Columns, ", ") + ")")
+	}
+
+	p.raw(" VALUES ")
+
+	for i, row := range s.Rows {
+		if i > 0 {
+			p.raw(", ")
+		}
+
+		p.raw("(")
+		p.list(row)
+		p.raw(")")
+	}
+
+	return p.buf.String()
+}
+
+// String returns the statement as SphinxQL.
+func (s *UpdateStmt) String() string {
+	p := &printer{}
+	p.raw("UPDATE " + s.Table + " SET ")
+
+	for i, a := range s.Set {
+		if i > 0 {
+			p.raw(", ")
+		}
+
+		p.raw(a.Column + " = ")
+		p.expr(a.Value)
+	}
+
+	if s.Where != nil {
+		p.raw(" WHERE ")
+		p.expr(s.Where)
+	}
+
+	if len(s.Options) > 0 {
+		p.raw(" OPTION ")
+		p.options(s.Options)
+	}
+
+	return p.buf.String()
+}
+
+// String returns the statement as SphinxQL.
+func (s *DeleteStmt) String() string {
+	p := &printer{}
+	p.raw("DELETE FROM " + s.Table)
+
+	if s.Where != nil {
+		p.raw(" WHERE ")
+		p.expr(s.Where)
+	}
+
+	return p.buf.String()
+}
+
+// String returns the statement as SphinxQL.
+func (s *CallStmt) String() string {
+	p := &printer{}
+	p.raw("CALL " + s.Name + "(")
+	p.list(s.Args)
+	p.raw(")")
+	return p.buf.String()
+}
@@ -0,0 +1,296 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrRankUnknownFactor means that a ranking expression refers to an unknown factor.
+	ErrRankUnknownFactor = errors.New("go-sphinxql: unknown ranking factor")
+
+	// ErrRankFieldFactorOutsideAggregate means that a field-level factor is used
+	// outside of an aggregate function like `sum` or `top`.
+	ErrRankFieldFactorOutsideAggregate = errors.New("go-sphinxql: field-level ranking factor outside of aggregate function")
+
+	// ErrRankNestedAggregate means that an aggregate function like `sum` or `top` is used inside of another one.
+	ErrRankNestedAggregate = errors.New("go-sphinxql: nested aggregate function in ranking expression")
+)
+
+// RankFactor is a name of a ranking factor.
+type RankFactor string
+
+// Document-level RankFactor enum
+const (
+	FactorBM25           RankFactor = "bm25"
+	FactorMaxLCS         RankFactor = "max_lcs"
+	FactorFieldMask      RankFactor = "field_mask"
+	FactorQueryWordCount RankFactor = "query_word_count"
+	FactorDocWordCount   RankFactor = "doc_word_count"
+)
+
+// Field-level RankFactor enum
+const (
+	FactorLCS            RankFactor = "lcs"
+	FactorUserWeight     RankFactor = "user_weight"
+	FactorHitCount       RankFactor = "hit_count"
+	FactorWordCount      RankFactor = "word_count"
+	FactorTFIDF          RankFactor = "tf_idf"
+	FactorMinHitPos      RankFactor = "min_hit_pos"
+	FactorMinBestSpanPos RankFactor = "min_best_span_pos"
+	FactorExactHit       RankFactor = "exact_hit"
+	FactorExactFieldHit  RankFactor = "exact_field_hit"
+	FactorExactOrder     RankFactor = "exact_order"
+	FactorMinIDF         RankFactor = "min_idf"
+	FactorMaxIDF         RankFactor = "max_idf"
+	FactorSumIDF         RankFactor = "sum_idf"
+	FactorMinGaps        RankFactor = "min_gaps"
+	FactorLCCS           RankFactor = "lccs"
+	FactorWLCCS          RankFactor = "wlccs"
+	FactorATC            RankFactor = "atc"
+)
+
+var rankFactorLevels = map[RankFactor]rankLevel{
+	FactorBM25:           rankLevelDocument,
+	FactorMaxLCS:         rankLevelDocument,
+	FactorFieldMask:      rankLevelDocument,
+	FactorQueryWordCount: rankLevelDocument,
+	FactorDocWordCount:   rankLevelDocument,
+
+	FactorLCS:            rankLevelField,
+	FactorUserWeight:     rankLevelField,
+	FactorHitCount:       rankLevelField,
+	FactorWordCount:      rankLevelField,
+	FactorTFIDF:          rankLevelField,
+	FactorMinHitPos:      rankLevelField,
+	FactorMinBestSpanPos: rankLevelField,
+	FactorExactHit:       rankLevelField,
+	FactorExactFieldHit:  rankLevelField,
+	FactorExactOrder:     rankLevelField,
+	FactorMinIDF:         rankLevelField,
+	FactorMaxIDF:         rankLevelField,
+	FactorSumIDF:         rankLevelField,
+	FactorMinGaps:        rankLevelField,
+	FactorLCCS:           rankLevelField,
+	FactorWLCCS:          rankLevelField,
+	FactorATC:            rankLevelField,
+}
+
+// IsFieldLevel returns true if f is a field-level factor,
+// which must be used inside of an aggregate function.
+func (f RankFactor) IsFieldLevel() bool {
+	return rankFactorLevels[f] == rankLevelField
+}
+
+// IsValid returns true if f is a known factor.
+func (f RankFactor) IsValid() bool {
+	_, ok := rankFactorLevels[f]
+	return ok
+}
+
+type rankLevel int
+
+const (
+	rankLevelDocument rankLevel = iota
+	rankLevelField
+)
+
+const (
+	rankPrecAtom = iota
+	rankPrecAdd
+	rankPrecMul
+)
+
+// RankExpr is a ranking expression which can be used in `Opt#ExprRanker` and `Opt#ExportRanker`.
+//
+// RankExpr is immutable. All methods return a new RankExpr.
+// Errors, e.g. unknown factors, are kept in the expression and reported by `RankExpr#Err`.
+type RankExpr struct {
+	expr      string
+	prec      int
+	level     rankLevel
+	aggregate bool
+	err       error
+}
+
+// Rank returns an expression with a single ranking factor.
+func Rank(factor RankFactor) RankExpr {
+	level, ok := rankFactorLevels[factor]
+
+	if !ok {
+		return RankExpr{
+			expr: string(factor),
+			err:  fmt.Errorf("%w: %s", ErrRankUnknownFactor, factor),
+		}
+	}
+
+	return RankExpr{
+		expr:  string(factor),
+		level: level,
+	}
+}
+
+// RankNum returns a numeric constant expression.
+// A negative value is wrapped in parentheses, e.g. "bm25*(-1)".
+func RankNum(value float64) RankExpr {
+	expr := formatRankNum(value)
+
+	if strings.HasPrefix(expr, "-") {
+		expr = "(" + expr + ")"
+	}
+
+	return RankExpr{
+		expr: expr,
+	}
+}
+
+func formatRankNum(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// RankBM25A returns a document-level "bm25a(k1, b)" expression.
+func RankBM25A(k1, b float64) RankExpr {
+	return RankExpr{
+		expr: fmt.Sprintf("bm25a(%s, %s)", formatRankNum(k1), formatRankNum(b)),
+	}
+}
+
+// RankBM25F returns a document-level "bm25f(k1, b, {field=weight, ...})" expression.
+// The weights are optional.
+func RankBM25F(k1, b float64, weights NamedIntegerList) RankExpr {
+	if len(weights) == 0 {
+		return RankExpr{
+			expr: fmt.Sprintf("bm25f(%s, %s)", formatRankNum(k1), formatRankNum(b)),
+		}
+	}
+
+	ks := make([]string, 0, len(weights))
+
+	for k := range weights {
+		ks = append(ks, k)
+	}
+
+	sort.Strings(ks)
+	nl := make([]string, 0, len(ks))
+
+	for _, k := range ks {
+		nl = append(nl, fmt.Sprintf("%s=%d", k, weights[k]))
+	}
+
+	return RankExpr{
+		expr: fmt.Sprintf("bm25f(%s, %s, {%s})", formatRankNum(k1), formatRankNum(b), strings.Join(nl, ", ")),
+	}
+}
+
+// RankMaxWindowHits returns a field-level "max_window_hits(n)" expression.
+func RankMaxWindowHits(n int) RankExpr {
+	return RankExpr{
+		expr:  fmt.Sprintf("max_window_hits(%d)", n),
+		level: rankLevelField,
+	}
+}
+
+// RankSum returns an aggregate "sum(expr)" expression, which sums expr over all matched fields.
+func RankSum(expr RankExpr) RankExpr {
+	return rankAggregate("sum", expr)
+}
+
+// RankTop returns an aggregate "top(expr)" expression, which returns the greatest expr over all matched fields.
+func RankTop(expr RankExpr) RankExpr {
+	return rankAggregate("top", expr)
+}
+
+func rankAggregate(name string, expr RankExpr) RankExpr {
+	err := expr.err
+
+	if err == nil && expr.aggregate {
+		err = fmt.Errorf("%w: %s(%s)", ErrRankNestedAggregate, name, expr.expr)
+	}
+
+	return RankExpr{
+		expr:      fmt.Sprintf("%s(%s)", name, expr.expr),
+		aggregate: true,
+		err:       err,
+	}
+}
+
+// Add represents "e + other".
+func (e RankExpr) Add(other RankExpr) RankExpr {
+	return e.binary("+", rankPrecAdd, other)
+}
+
+// Sub represents "e - other".
+func (e RankExpr) Sub(other RankExpr) RankExpr {
+	return e.binary("-", rankPrecAdd, other)
+}
+
+// Mul represents "e * other".
+func (e RankExpr) Mul(other RankExpr) RankExpr {
+	return e.binary("*", rankPrecMul, other)
+}
+
+// Div represents "e / other".
+func (e RankExpr) Div(other RankExpr) RankExpr {
+	return e.binary("/", rankPrecMul, other)
+}
+
+func (e RankExpr) binary(op string, prec int, other RankExpr) RankExpr {
+	left := e.expr
+	right := other.expr
+
+	if e.prec != rankPrecAtom && e.prec < prec {
+		left = "(" + left + ")"
+	}
+
+	// The right operand must be wrapped for non-associative operators, e.g. "a - (b + c)".
+	if other.prec != rankPrecAtom && other.prec <= prec {
+		right = "(" + right + ")"
+	}
+
+	level := e.level
+
+	if other.level > level {
+		level = other.level
+	}
+
+	err := e.err
+
+	if err == nil {
+		err = other.err
+	}
+
+	return RankExpr{
+		expr:      left + op + right,
+		prec:      prec,
+		level:     level,
+		aggregate: e.aggregate || other.aggregate,
+		err:       err,
+	}
+}
+
+// Err returns the first error found in e.
+// It returns nil if e is a valid document-level expression.
+func (e RankExpr) Err() error {
+	if e.err != nil {
+		return e.err
+	}
+
+	if e.level == rankLevelField {
+		return fmt.Errorf("%w: %s", ErrRankFieldFactorOutsideAggregate, e.expr)
+	}
+
+	return nil
+}
+
+// String returns the compiled expression, e.g. "sum(lcs*user_weight)*1000+bm25".
+// Call `RankExpr#Err` to make sure the expression is valid.
+func (e RankExpr) String() string {
+	return e.expr
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func ExampleRankExpr() {
+	expr := RankSum(Rank(FactorLCS).Mul(Rank(FactorUserWeight))).Mul(RankNum(1000)).Add(Rank(FactorBM25))
+
+	sb := NewSelectBuilder()
+	sb.Select("id", sb.As("WEIGHT()", "w"))
+	sb.From("vacancies")
+	sb.Where(sb.Match("golang"))
+	sb.OrderBy(sb.Desc("w"))
+	sb.Option(sb.ExprRanker(expr.String()))
+
+	s, args := sb.Build()
+	fmt.Println(expr.Err())
+	fmt.Println(s)
+	fmt.Println(args)
+
+	// Output:
+	// <nil>
+	// SELECT id, WEIGHT() AS w FROM vacancies WHERE MATCH(?) ORDER BY w DESC OPTION ranker = expr(?)
+	// [golang sum(lcs*user_weight)*1000+bm25]
+}
+
+func TestRankExpr(t *testing.T) {
+	a := assert.New(t)
+	cases := map[string]RankExpr{
+		"bm25":                                 Rank(FactorBM25),
+		"0.5":                                  RankNum(0.5),
+		"bm25a(1.2, 0.75)":                     RankBM25A(1.2, 0.75),
+		"bm25f(1.2, 0.75)":                     RankBM25F(1.2, 0.75, nil),
+		"bm25f(1.2, 0.75, {body=1, title=3})":  RankBM25F(1.2, 0.75, NamedIntegerList{"title": 3, "body": 1}),
+		"top(max_window_hits(3))":              RankTop(RankMaxWindowHits(3)),
+		"(bm25+1)*2":                           Rank(FactorBM25).Add(RankNum(1)).Mul(RankNum(2)),
+		"bm25*2+1":                             Rank(FactorBM25).Mul(RankNum(2)).Add(RankNum(1)),
+		"bm25-(max_lcs-1)":                     Rank(FactorBM25).Sub(Rank(FactorMaxLCS).Sub(RankNum(1))),
+		"bm25/(2*query_word_count)":            Rank(FactorBM25).Div(RankNum(2).Mul(Rank(FactorQueryWordCount))),
+		"bm25-(-1)":                            Rank(FactorBM25).Sub(RankNum(-1)),
+		"(-0.5)*bm25":                          RankNum(-0.5).Mul(Rank(FactorBM25)),
+		"bm25a(-1.2, 0.75)":                    RankBM25A(-1.2, 0.75),
+		"sum(lcs)+top(exact_hit)":              RankSum(Rank(FactorLCS)).Add(RankTop(Rank(FactorExactHit))),
+		"sum((word_count+exact_hit)*atc)+bm25": RankSum(Rank(FactorWordCount).Add(Rank(FactorExactHit)).Mul(Rank(FactorATC))).Add(Rank(FactorBM25)),
+	}
+
+	for expected, expr := range cases {
+		a.Use(&expected)
+		a.Equal(expr.String(), expected)
+		a.NilError(expr.Err())
+	}
+
+	errCases := map[error]RankExpr{
+		ErrRankUnknownFactor:               RankSum(Rank("lcs_typo")).Add(Rank(FactorBM25)),
+		ErrRankFieldFactorOutsideAggregate: Rank(FactorLCS).Mul(RankNum(1000)).Add(Rank(FactorBM25)),
+		ErrRankNestedAggregate:             RankSum(RankTop(Rank(FactorLCS))),
+	}
+
+	for expected, expr := range errCases {
+		a.Use(&expected)
+		a.Assert(errors.Is(expr.Err(), expected))
+	}
+
+	a.Assert(FactorLCS.IsValid())
+	a.Assert(FactorLCS.IsFieldLevel())
+	a.Assert(!FactorBM25.IsFieldLevel())
+	a.Assert(!RankFactor("unknown").IsValid())
+}
@@ -0,0 +1,180 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrScanUnknownColumn means that a column of rows cannot be mapped to any field of a struct.
+	ErrScanUnknownColumn = errors.New("go-sphinxql: no struct field for column")
+
+	// ErrScanInvalidDest means that the destination of `Struct#ScanRow` or `Struct#ScanRows`
+	// is not a pointer to the struct or a pointer to a slice of the struct.
+	ErrScanInvalidDest = errors.New("go-sphinxql: invalid scan destination")
+)
+
+// Rows is a cursor of a query result.
+// It's implemented by `*sql.Rows`.
+type Rows interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+}
+
+// ScanMode controls how `Struct#ScanRow` and `Struct#ScanRows` handle columns without struct fields.
+type ScanMode int
+
+// ScanMode enum.
+const (
+	// ScanStrict fails with ErrScanUnknownColumn if a column has no field.
+	ScanStrict ScanMode = iota
+
+	// ScanLenient discards values of columns without fields, e.g. the implicit "id"
+	// of searchd or an expression like "WEIGHT() AS w" which is used only for sorting.
+	ScanLenient
+)
+
+// WithScanMode returns a new Struct based on s with the scan mode.
+// The original s is not changed.
+func (s *Struct) WithScanMode(mode ScanMode) *Struct {
+	if s.structType == nil {
+		return &emptyStruct
+	}
+
+	c := *s
+	c.scanMode = mode
+	return &c
+}
+
+// ScanRow scans the current row of rows into dest, which must be a pointer to the struct of s.
+// Columns are mapped to fields by names, so the order of columns in SELECT doesn't matter.
+// Columns without fields are handled according to the scan mode set by `Struct#WithScanMode`.
+//
+// Like `Rows#Scan`, caller must call `Rows#Next` before ScanRow.
+func (s *Struct) ScanRow(rows Rows, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Type() != s.structType {
+		return fmt.Errorf("%w: expected *%v, got %T", ErrScanInvalidDest, s.structType, dest)
+	}
+
+	cols, err := rows.Columns()
+
+	if err != nil {
+		return err
+	}
+
+	fields, err := s.scanFields(cols)
+
+	if err != nil {
+		return err
+	}
+
+	return rows.Scan(s.scanAddrs(cols, fields, v.Elem())...)
+}
+
+// ScanRows reads all rows and appends them to dest.
+// The dest must be a pointer to a slice of the struct of s or a slice of pointers to the struct.
+// Columns are mapped to fields by names in the same way as `Struct#ScanRow`.
+//
+// Caller is responsible to close rows.
+func (s *Struct) ScanRows(rows Rows, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("%w: expected *[]%v, got %T", ErrScanInvalidDest, s.structType, dest)
+	}
+
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	if elemType != s.structType {
+		return fmt.Errorf("%w: expected *[]%v, got %T", ErrScanInvalidDest, s.structType, dest)
+	}
+
+	cols, err := rows.Columns()
+
+	if err != nil {
+		return err
+	}
+
+	fields, err := s.scanFields(cols)
+
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		elem := reflect.New(elemType)
+
+		if err := rows.Scan(s.scanAddrs(cols, fields, elem.Elem())...); err != nil {
+			return err
+		}
+
+		if isPtr {
+			slice = reflect.Append(slice, elem)
+		} else {
+			slice = reflect.Append(slice, elem.Elem())
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	v.Elem().Set(slice)
+	return nil
+}
+
+// scanFields returns names of fields for cols.
+// An empty name means the column should be discarded.
+func (s *Struct) scanFields(cols []string) ([]string, error) {
+	if s.structType == nil {
+		return nil, fmt.Errorf("%w: %v", ErrScanUnknownColumn, cols)
+	}
+
+	sf := s.structFieldsParser()
+	fields := make([]string, 0, len(cols))
+
+	for _, c := range cols {
+		name, ok := sf.fieldAlias[c]
+
+		if !ok && s.scanMode == ScanStrict {
+			return nil, fmt.Errorf("%w: %s", ErrScanUnknownColumn, c)
+		}
+
+		fields = append(fields, name)
+	}
+
+	return fields, nil
+}
+
+// scanAddrs takes addresses of fields for cols from v.
+// A discarded column is scanned into a throwaway value.
+func (s *Struct) scanAddrs(cols, fields []string, v reflect.Value) []interface{} {
+	sf := s.structFieldsParser()
+	addrs := make([]interface{}, 0, len(fields))
+
+	for i, name := range fields {
+		if name == "" {
+			addrs = append(addrs, new(interface{}))
+			continue
+		}
+
+		addrs = append(addrs, s.fieldAddr(sf, cols[i], v.FieldByName(name)))
+	}
+
+	return addrs
+}
@@ -0,0 +1,154 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+// memRows is a fake `Rows` holding all values in memory.
+type memRows struct {
+	cols []string
+	rows [][]interface{}
+	curr int
+	err  error
+}
+
+func (r *memRows) Columns() ([]string, error) {
+	return r.cols, nil
+}
+
+func (r *memRows) Next() bool {
+	r.curr++
+	return r.curr <= len(r.rows)
+}
+
+func (r *memRows) Scan(dest ...interface{}) error {
+	row := r.rows[r.curr-1]
+
+	if len(dest) != len(row) {
+		return fmt.Errorf("expected %d destinations, got %d", len(row), len(dest))
+	}
+
+	for i, v := range row {
+		switch d := dest[i].(type) {
+		case *int:
+			*d = v.(int)
+		case *string:
+			*d = v.(string)
+		case *interface{}:
+			*d = v
+		case sql.Scanner:
+			if err := d.Scan(v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported destination %T", d)
+		}
+	}
+
+	return nil
+}
+
+func (r *memRows) Err() error {
+	return r.err
+}
+
+func ExampleStruct_ScanRows() {
+	type Vacancy struct {
+		ID    int    `db:"id"`
+		Title string `db:"title"`
+	}
+
+	vacancies := NewStruct(Vacancy{}).WithScanMode(ScanLenient)
+
+	sb := vacancies.SelectFrom("vacancies")
+	sb.Select("title", "id", sb.As("WEIGHT()", "w"))
+	sb.Where(sb.Match("golang"))
+	sb.OrderBy(sb.Desc("w"))
+
+	// Rows returned by `*sql.DB#Query`.
+	rows := &memRows{
+		cols: []string{"title", "id", "w"},
+		rows: [][]interface{}{
+			{"Go developer", 1, 2500},
+			{"Senior Go developer", 2, 1500},
+		},
+	}
+
+	var result []Vacancy
+	err := vacancies.ScanRows(rows, &result)
+	fmt.Println(result, err)
+
+	// Output:
+	// [{1 Go developer} {2 Senior Go developer}] <nil>
+}
+
+func TestStructScanRow(t *testing.T) {
+	a := assert.New(t)
+	rows := &memRows{
+		cols: []string{"created_at", "Name", "id"},
+		rows: [][]interface{}{{1234567890, "Huan Du", 123}},
+	}
+	var user structUserForTest
+
+	a.Assert(rows.Next())
+	a.NilError(userForTest.ScanRow(rows, &user))
+	a.Equal(user, structUserForTest{ID: 123, Name: "Huan Du", CreatedAt: 1234567890})
+
+	rows = &memRows{
+		cols: []string{"id", "w"},
+		rows: [][]interface{}{{123, 2500}},
+	}
+	user = structUserForTest{}
+
+	a.Assert(rows.Next())
+	a.Assert(errors.Is(userForTest.ScanRow(rows, &user), ErrScanUnknownColumn))
+	a.NilError(userForTest.WithScanMode(ScanLenient).ScanRow(rows, &user))
+	a.Equal(user, structUserForTest{ID: 123})
+
+	a.Assert(errors.Is(userForTest.ScanRow(rows, user), ErrScanInvalidDest))
+	a.Assert(errors.Is(userForTest.ScanRow(rows, new(int)), ErrScanInvalidDest))
+	a.Assert(errors.Is(userForTest.ScanRow(rows, (*structUserForTest)(nil)), ErrScanInvalidDest))
+}
+
+func TestStructScanRows(t *testing.T) {
+	a := assert.New(t)
+	newRows := func() *memRows {
+		return &memRows{
+			cols: []string{"status", "id"},
+			rows: [][]interface{}{{1, 10}, {2, 20}},
+		}
+	}
+
+	var users []structUserForTest
+	a.NilError(userForTest.ScanRows(newRows(), &users))
+	a.Equal(users, []structUserForTest{{ID: 10, Status: 1}, {ID: 20, Status: 2}})
+
+	// Rows are appended.
+	a.NilError(userForTest.ScanRows(newRows(), &users))
+	a.Equal(len(users), 4)
+
+	var ptrs []*structUserForTest
+	a.NilError(userForTest.ScanRows(newRows(), &ptrs))
+	a.Equal(ptrs, []*structUserForTest{{ID: 10, Status: 1}, {ID: 20, Status: 2}})
+
+	a.Assert(errors.Is(userForTest.ScanRows(newRows(), users), ErrScanInvalidDest))
+	a.Assert(errors.Is(userForTest.ScanRows(newRows(), &[]int{}), ErrScanInvalidDest))
+
+	errRows := errors.New("connection lost")
+	rows := newRows()
+	rows.err = errRows
+	a.Equal(userForTest.ScanRows(rows, &users), errRows)
+
+	rows = newRows()
+	rows.cols = append(rows.cols, "w")
+	a.Assert(errors.Is(userForTest.ScanRows(rows, &users), ErrScanUnknownColumn))
+}
@@ -5,6 +5,7 @@
 package sphinxql
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -17,11 +18,20 @@ const (
 	selectMarkerAfterWhere
 	selectMarkerAfterGroupBy
 	selectMarkerAfterWithinGroupOrderBy
+	selectMarkerAfterHaving
 	selectMarkerAfterOrderBy
 	selectMarkerAfterLimit
 	selectMarkerAfterOption
 )
 
+var (
+	// ErrWithinGroupOrderByWithoutGroupBy means that WITHIN GROUP ORDER BY is used in a query without GROUP BY.
+	ErrWithinGroupOrderByWithoutGroupBy = errors.New("go-sphinxql: WITHIN GROUP ORDER BY without GROUP BY")
+
+	// ErrHavingWithoutGroupBy means that HAVING is used in a query without GROUP BY.
+	ErrHavingWithoutGroupBy = errors.New("go-sphinxql: HAVING without GROUP BY")
+)
+
 // NewSelectBuilder creates a new SELECT builder.
 func NewSelectBuilder() *SelectBuilder {
 	return DefaultFlavor.NewSelectBuilder()
@@ -48,10 +58,12 @@ type SelectBuilder struct {
 	Cond
 	OrdBy
 	Opt
+	Agg
 
 	tables                  []string
 	selectCols              []string
 	whereExprs              []string
+	groupByN                int
 	groupByCols             []string
 	withinGroupOrderByExprs []string
 	havingExprs             []string
@@ -59,6 +71,7 @@ type SelectBuilder struct {
 	limit                   int
 	offset                  int
 	optionExprs             []string
+	maxMatchesPolicy        *MaxMatchesPolicy
 
 	args *Args
 
@@ -88,21 +101,34 @@ func (sb *SelectBuilder) From(table ...string) *SelectBuilder {
 }
 
 // Where sets expressions of WHERE in SELECT.
+// Expression trees can be added by `SelectBuilder#WhereExpr`.
+// Empty expressions, e.g. a tree pruned to nothing, are skipped.
 func (sb *SelectBuilder) Where(andExpr ...string) *SelectBuilder {
-	sb.whereExprs = append(sb.whereExprs, andExpr...)
+	sb.whereExprs = appendNonEmpty(sb.whereExprs, andExpr)
 	sb.marker = selectMarkerAfterWhere
 	return sb
 }
 
 // Having sets expressions of HAVING in SELECT.
+// Expression trees can be added by `SelectBuilder#HavingExpr`. Empty expressions are skipped.
 func (sb *SelectBuilder) Having(andExpr ...string) *SelectBuilder {
-	sb.havingExprs = append(sb.havingExprs, andExpr...)
-	sb.marker = selectMarkerAfterGroupBy
+	sb.havingExprs = appendNonEmpty(sb.havingExprs, andExpr)
+	sb.marker = selectMarkerAfterHaving
 	return sb
 }
 
 // GroupBy sets columns of GROUP BY in SELECT.
 func (sb *SelectBuilder) GroupBy(col ...string) *SelectBuilder {
+	sb.groupByN = 0
+	sb.groupByCols = col
+	sb.marker = selectMarkerAfterGroupBy
+	return sb
+}
+
+// GroupNBy sets columns of GROUP N BY in SELECT.
+// Up to n best documents are kept in every group instead of one.
+func (sb *SelectBuilder) GroupNBy(n int, col ...string) *SelectBuilder {
+	sb.groupByN = n
 	sb.groupByCols = col
 	sb.marker = selectMarkerAfterGroupBy
 	return sb
@@ -143,6 +169,37 @@ func (sb *SelectBuilder) Option(optionExpr ...string) *SelectBuilder {
 	return sb
 }
 
+// setOption replaces the option with name in OPTION by expr or adds expr if there is no such option.
+func (sb *SelectBuilder) setOption(name string, expr string) {
+	exprs := make([]string, 0, len(sb.optionExprs)+1)
+	found := false
+
+	for _, e := range sb.optionExprs {
+		if optionName(e) == name {
+			e = expr
+			found = true
+		}
+
+		exprs = append(exprs, e)
+	}
+
+	if !found {
+		exprs = append(exprs, expr)
+	}
+
+	sb.optionExprs = exprs
+	sb.marker = selectMarkerAfterOption
+}
+
+// optionName returns the name of an OPTION expression like "name = value".
+func optionName(expr string) string {
+	if i := strings.IndexByte(expr, '='); i >= 0 {
+		expr = expr[:i]
+	}
+
+	return strings.ToLower(strings.TrimSpace(expr))
+}
+
 // As returns an AS expression.
 func (sb *SelectBuilder) As(name, alias string) string {
 	return fmt.Sprintf("%s AS %s", name, alias)
@@ -156,10 +213,25 @@ func (sb *SelectBuilder) BuilderAs(builder Builder, alias string) string {
 
 // String returns the compiled SELECT string.
 func (sb *SelectBuilder) String() string {
-	s, _ := sb.Build()
+	s, _ := sb.build(sb.args.Flavor)
 	return s
 }
 
+// StringInterpolated returns the compiled SELECT string with args interpolated.
+// Like String, it doesn't call hooks. It's designed for logging and debugging;
+// use BuildInterpolated to send a query, as the error of interpolation is returned in place of the query.
+func (sb *SelectBuilder) StringInterpolated() string {
+	sql, args := sb.build(sb.args.Flavor)
+	return interpolatedString(sb.args.Flavor, sql, args)
+}
+
+// BuildInterpolated returns compiled SELECT string with args interpolated by the flavor of sb.
+// It's designed for searchd, which doesn't support server-side prepared statements.
+func (sb *SelectBuilder) BuildInterpolated() (string, error) {
+	sql, args := sb.Build()
+	return sb.args.Flavor.Interpolate(sql, args)
+}
+
 // Build returns compiled SELECT string and args.
 // They can be used in `DB#Query` of package `database/sql` directly.
 func (sb *SelectBuilder) Build() (sql string, args []interface{}) {
@@ -169,6 +241,12 @@ func (sb *SelectBuilder) Build() (sql string, args []interface{}) {
 // BuildWithFlavor returns compiled SELECT string and args with flavor and initial args.
 // They can be used in `DB#Query` of package `database/sql` directly.
 func (sb *SelectBuilder) BuildWithFlavor(flavor Flavor, initialArg ...interface{}) (sql string, args []interface{}) {
+	sql, args = sb.build(flavor, initialArg...)
+	return runHooks(sb, flavor, sql, args)
+}
+
+// build builds sb without calling hooks.
+func (sb *SelectBuilder) build(flavor Flavor, initialArg ...interface{}) (sql string, args []interface{}) {
 	buf := &strings.Builder{}
 	sb.injection.WriteTo(buf, selectMarkerInit)
 	buf.WriteString("SELECT ")
@@ -188,14 +266,16 @@ func (sb *SelectBuilder) BuildWithFlavor(flavor Flavor, initialArg ...interface{
 	}
 
 	if len(sb.groupByCols) > 0 {
-		buf.WriteString(" GROUP BY ")
-		buf.WriteString(strings.Join(sb.groupByCols, ", "))
-
-		if len(sb.havingExprs) > 0 {
-			buf.WriteString(" HAVING ")
-			buf.WriteString(strings.Join(sb.havingExprs, " AND "))
+		if sb.groupByN > 0 {
+			buf.WriteString(" GROUP ")
+			buf.WriteString(strconv.Itoa(sb.groupByN))
+			buf.WriteString(" BY ")
+		} else {
+			buf.WriteString(" GROUP BY ")
 		}
 
+		buf.WriteString(strings.Join(sb.groupByCols, ", "))
+
 		sb.injection.WriteTo(buf, selectMarkerAfterGroupBy)
 	}
 
@@ -206,6 +286,14 @@ func (sb *SelectBuilder) BuildWithFlavor(flavor Flavor, initialArg ...interface{
 		sb.injection.WriteTo(buf, selectMarkerAfterWithinGroupOrderBy)
 	}
 
+	// The searchd requires HAVING after WITHIN GROUP ORDER BY.
+	if len(sb.groupByCols) > 0 && len(sb.havingExprs) > 0 {
+		buf.WriteString(" HAVING ")
+		buf.WriteString(strings.Join(sb.havingExprs, " AND "))
+
+		sb.injection.WriteTo(buf, selectMarkerAfterHaving)
+	}
+
 	if len(sb.orderByExprs) > 0 {
 		buf.WriteString(" ORDER BY ")
 		buf.WriteString(strings.Join(sb.orderByExprs, ", "))
@@ -225,9 +313,9 @@ func (sb *SelectBuilder) BuildWithFlavor(flavor Flavor, initialArg ...interface{
 		sb.injection.WriteTo(buf, selectMarkerAfterLimit)
 	}
 
-	if len(sb.optionExprs) > 0 {
+	if optionExprs := sb.buildOptionExprs(flavor); len(optionExprs) > 0 {
 		buf.WriteString(" OPTION ")
-		buf.WriteString(strings.Join(sb.optionExprs, ", "))
+		buf.WriteString(strings.Join(optionExprs, ", "))
 
 		sb.injection.WriteTo(buf, selectMarkerAfterOption)
 	}
@@ -235,6 +323,35 @@ func (sb *SelectBuilder) BuildWithFlavor(flavor Flavor, initialArg ...interface{
 	return sb.args.CompileWithFlavor(buf.String(), flavor, initialArg...)
 }
 
+// Validate checks sb for mistakes which are not reported until the query reaches the server.
+// It returns the first found error or nil.
+func (sb *SelectBuilder) Validate() error {
+	return sb.ValidateWithFlavor(sb.args.Flavor)
+}
+
+// ValidateWithFlavor checks sb like Validate with settings of flavor,
+// which must be the flavor passed to `BuildWithFlavor`.
+func (sb *SelectBuilder) ValidateWithFlavor(flavor Flavor) error {
+	// HAVING is checked first, as it's dropped from the query silently.
+	if len(sb.havingExprs) > 0 && len(sb.groupByCols) == 0 {
+		return ErrHavingWithoutGroupBy
+	}
+
+	if len(sb.withinGroupOrderByExprs) > 0 && len(sb.groupByCols) == 0 {
+		return ErrWithinGroupOrderByWithoutGroupBy
+	}
+
+	if err := analyzeMatch(strings.Join(sb.whereExprs, " AND ")).err(); err != nil {
+		return err
+	}
+
+	return validateEmptyList(sb.args, flavor)
+}
+
+func (sb *SelectBuilder) defaultFlavor() Flavor {
+	return sb.args.Flavor
+}
+
 // SetFlavor sets the flavor of compiled sql.
 func (sb *SelectBuilder) SetFlavor(flavor Flavor) (old Flavor) {
 	old = sb.args.Flavor
@@ -242,6 +359,46 @@ func (sb *SelectBuilder) SetFlavor(flavor Flavor) (old Flavor) {
 	return
 }
 
+// Clone returns a deep copy of sb.
+// Args, injected SQLs and nested builders are copied, so sb and the copy
+// can be modified and built independently, e.g. in different goroutines.
+func (sb *SelectBuilder) Clone() *SelectBuilder {
+	args := sb.args.clone()
+	c := &SelectBuilder{
+		Cond: Cond{
+			Args: args,
+		},
+		Opt: Opt{
+			Args: args,
+		},
+		tables:                  cloneStrings(sb.tables),
+		selectCols:              cloneStrings(sb.selectCols),
+		whereExprs:              cloneStrings(sb.whereExprs),
+		groupByN:                sb.groupByN,
+		groupByCols:             cloneStrings(sb.groupByCols),
+		withinGroupOrderByExprs: cloneStrings(sb.withinGroupOrderByExprs),
+		havingExprs:             cloneStrings(sb.havingExprs),
+		orderByExprs:            cloneStrings(sb.orderByExprs),
+		limit:                   sb.limit,
+		offset:                  sb.offset,
+		optionExprs:             cloneStrings(sb.optionExprs),
+		args:                    args,
+		injection:               sb.injection.clone(),
+		marker:                  sb.marker,
+	}
+
+	if sb.maxMatchesPolicy != nil {
+		policy := *sb.maxMatchesPolicy
+		c.maxMatchesPolicy = &policy
+	}
+
+	return c
+}
+
+func (sb *SelectBuilder) cloneBuilder() Builder {
+	return sb.Clone()
+}
+
 // SQL adds an arbitrary sql to current position.
 func (sb *SelectBuilder) SQL(sql string) *SelectBuilder {
 	sb.injection.SQL(sb.marker, sql)
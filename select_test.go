@@ -7,6 +7,9 @@ package sphinxql
 import (
 	"database/sql"
 	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
 )
 
 func ExampleSelect() {
@@ -56,7 +59,7 @@ func ExampleSelectBuilder() {
 	fmt.Println(args)
 
 	// Output:
-	// SELECT id, name, COUNT(*) AS t FROM demo.user WHERE MATCH(?) AND id > ? AND name LIKE ? AND (id_card IS NULL OR status IN (?, ?, ?)) AND id NOT IN (SELECT id FROM banned) AND modified_at > created_at + ? GROUP BY status HAVING status NOT IN (?, ?) WITHIN GROUP ORDER BY status DESC ORDER BY modified_at ASC LIMIT 5,10 OPTION comment = ?, ranker = ?
+	// SELECT id, name, COUNT(*) AS t FROM demo.user WHERE MATCH(?) AND id > ? AND name LIKE ? AND (id_card IS NULL OR status IN (?, ?, ?)) AND id NOT IN (SELECT id FROM banned) AND modified_at > created_at + ? GROUP BY status WITHIN GROUP ORDER BY status DESC HAVING status NOT IN (?, ?) ORDER BY modified_at ASC LIMIT 5,10 OPTION comment = ?, ranker = ?
 	// [@(name) test 1234 %Du 1 2 5 86400 4 5 kekw wordcount]
 }
 
@@ -191,3 +194,153 @@ func ExampleSelectBuilder_SQL() {
 	// Output:
 	// /* before */ SELECT u.id, u.name, c.type, p.nickname /* after select */ FROM user u /* after from */ WHERE u.modified_at > u.created_at /* after where */ ORDER BY id /* after order by */ LIMIT 10 /* after limit */
 }
+
+func ExampleSelectBuilder_GroupNBy() {
+	sb := NewSelectBuilder()
+	sb.Select("id", "company_id", sb.As(sb.Count(""), "cnt"), sb.As(sb.GroupConcat("id"), "ids"))
+	sb.From("vacancies")
+	sb.Where(sb.Match("golang"))
+	sb.GroupNBy(3, "company_id")
+	sb.WithinGroupOrderBy(sb.Desc("salary"))
+	sb.OrderBy(sb.Desc(MagicCount))
+
+	s, args := sb.Build()
+	fmt.Println(s)
+	fmt.Println(args)
+	fmt.Println(sb.Validate())
+
+	// Output:
+	// SELECT id, company_id, COUNT(*) AS cnt, GROUP_CONCAT(id) AS ids FROM vacancies WHERE MATCH(?) GROUP 3 BY company_id WITHIN GROUP ORDER BY salary DESC ORDER BY @count DESC
+	// [golang]
+	// <nil>
+}
+
+func ExampleSelectBuilder_BuildInterpolated() {
+	sb := NewSelectBuilder()
+	sb.Select("id").From("vacancies")
+	sb.Where(sb.Match("it's golang"), sb.In("city_id", 1, 2))
+	sb.Limit(10)
+
+	s, err := sb.BuildInterpolated()
+	fmt.Println(s)
+	fmt.Println(err)
+
+	// Output:
+	// SELECT id FROM vacancies WHERE MATCH('it\'s golang') AND city_id IN (1, 2) LIMIT 10
+	// <nil>
+}
+
+func TestSelectBuilderValidate(t *testing.T) {
+	a := assert.New(t)
+	sb := NewSelectBuilder()
+	sb.Select("id").From("vacancies")
+	a.NilError(sb.Validate())
+
+	sb.WithinGroupOrderBy(sb.Desc("salary"))
+	a.Equal(sb.Validate(), ErrWithinGroupOrderByWithoutGroupBy)
+
+	sb.GroupBy("company_id")
+	a.NilError(sb.Validate())
+	a.Equal(sb.String(), "SELECT id FROM vacancies GROUP BY company_id WITHIN GROUP ORDER BY salary DESC")
+
+	sb.GroupNBy(2, "company_id", "city_id")
+	a.Equal(sb.String(), "SELECT id FROM vacancies GROUP 2 BY company_id, city_id WITHIN GROUP ORDER BY salary DESC")
+
+	sb = NewSelectBuilder()
+	sb.Select("id", sb.As("COUNT(*)", "cnt")).From("vacancies").Having("cnt > 1")
+	a.Equal(sb.Validate(), ErrHavingWithoutGroupBy)
+	a.Equal(sb.String(), "SELECT id, COUNT(*) AS cnt FROM vacancies")
+}
+
+func TestSelectBuilderGroupClauseOrder(t *testing.T) {
+	a := assert.New(t)
+	sb := NewSelectBuilder()
+	sb.Select("id", sb.As("COUNT(*)", "c"), sb.As("WEIGHT()", "w")).From("vacancies")
+	sb.Having(sb.GreaterThan("c", 1))
+	sb.SQL("/* having */")
+	sb.WithinGroupOrderBy(sb.Desc("w"))
+	sb.GroupNBy(3, "company_id")
+	sb.OrderBy(sb.Desc("c"))
+
+	// The searchd requires HAVING after WITHIN GROUP ORDER BY regardless of the order of calls.
+	s, args := sb.Build()
+	a.Equal(s, "SELECT id, COUNT(*) AS c, WEIGHT() AS w FROM vacancies GROUP 3 BY company_id WITHIN GROUP ORDER BY w DESC HAVING c > ? /* having */ ORDER BY c DESC")
+	a.Equal(args, []interface{}{1})
+	a.NilError(sb.Validate())
+}
+
+func TestSelectBuilderClone(t *testing.T) {
+	a := assert.New(t)
+	inner := NewSelectBuilder().Select("id").From("banned")
+	base := NewSelectBuilder()
+	base.SQL("/* base */")
+	base.Select("id", "title").From("vacancies")
+	base.Where(base.Match("golang"), base.NotIn("company_id", inner))
+	base.Where(base.E("status", sql.Named("status", 1)))
+	base.GroupNBy(2, "company_id").WithinGroupOrderBy(base.Desc("salary"))
+	base.OrderBy(base.Desc("WEIGHT()")).Limit(10).Offset(1000)
+	base.Option(base.Comment("base"))
+	base.AutoMaxMatches(MaxMatchesPolicy{Enabled: true})
+	baseSQL, baseArgs := base.Build()
+
+	c := base.Clone()
+	cSQL, cArgs := c.Build()
+	a.Equal(cSQL, baseSQL)
+	a.Equal(cArgs, baseArgs)
+
+	c.Select("id").Where(c.E("city_id", 2)).OrderBy(c.Asc("id")).Limit(20).Offset(-1)
+	c.Option(c.Comment("clone"), c.MaxMatches(100))
+	c.SQL("/* clone */")
+	c.AutoMaxMatches(MaxMatchesPolicy{})
+
+	s, args := base.Build()
+	a.Equal(s, baseSQL)
+	a.Equal(args, baseArgs)
+
+	// Nested builders are cloned too.
+	inner.Where("1 = 0")
+	a.Equal(base.String(), "/* base */ SELECT id, title FROM vacancies WHERE MATCH(?) AND company_id NOT IN (SELECT id FROM banned WHERE 1 = 0) AND status = @status GROUP 2 BY company_id WITHIN GROUP ORDER BY salary DESC ORDER BY WEIGHT() DESC LIMIT 1000,10 OPTION comment = ?, max_matches = 1010")
+
+	s, args = c.Build()
+	a.Equal(s, "/* base */ SELECT id FROM vacancies WHERE MATCH(?) AND company_id NOT IN (SELECT id FROM banned) AND status = @status AND city_id = ? GROUP 2 BY company_id WITHIN GROUP ORDER BY salary DESC ORDER BY id ASC LIMIT 20 OPTION comment = ?, max_matches = ? /* clone */")
+	a.Equal(args, []interface{}{"golang", 2, "clone", 100, sql.Named("status", 1)})
+}
+
+func TestSelectBuilderCloneList(t *testing.T) {
+	a := assert.New(t)
+	sub := NewSelectBuilder().Select("id").From("banned")
+	base := NewSelectBuilder()
+	base.Select("id").From("vacancies").Where(base.In("company_id", List([]interface{}{sub, 1})))
+
+	c := base.Clone()
+	sub.Where("1 = 0")
+	a.Equal(base.String(), "SELECT id FROM vacancies WHERE company_id IN (SELECT id FROM banned WHERE 1 = 0, ?)")
+	a.Equal(c.String(), "SELECT id FROM vacancies WHERE company_id IN (SELECT id FROM banned, ?)")
+}
+
+func TestSelectBuilderCloneConcurrently(t *testing.T) {
+	a := assert.New(t)
+	base := NewSelectBuilder()
+	base.Select("id").From("vacancies").Where(base.Match("golang"))
+	results := make(chan []interface{}, 10)
+
+	for i := 0; i < cap(results); i++ {
+		go func(i int) {
+			sb := base.Clone()
+			sb.Where(sb.E("city_id", i))
+			_, args := sb.Build()
+			results <- args
+		}(i)
+	}
+
+	seen := map[int]bool{}
+
+	for i := 0; i < cap(results); i++ {
+		args := <-results
+		a.Equal(len(args), 2)
+		seen[args[1].(int)] = true
+	}
+
+	a.Equal(len(seen), cap(results))
+	a.Equal(base.String(), "SELECT id FROM vacancies WHERE MATCH(?)")
+}
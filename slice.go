@@ -0,0 +1,157 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"strings"
+)
+
+// DefaultInSliceChunkSize is the default max number of values in one IN list rendered by `InSlice` and `NotInSlice`.
+const DefaultInSliceChunkSize = 4096
+
+// SetInSliceChunkSize sets the max number of values in one IN list rendered by `InSlice` and `NotInSlice`
+// for all builders with flavor f. Longer slices are split into several IN lists joined by OR
+// (or NOT IN lists joined by AND).
+// Zero restores DefaultInSliceChunkSize, and a negative value disables chunking.
+//
+// SetInSliceChunkSize is expected to be called once during initialization.
+func (f Flavor) SetInSliceChunkSize(size int) {
+	f.updateSettings(func(fs *flavorSettings) {
+		fs.inSliceChunkSize = size
+	})
+}
+
+// InSliceChunkSize returns the max number of values in one IN list of flavor f.
+// A negative value means that chunking is disabled.
+func (f Flavor) InSliceChunkSize() int {
+	if size := f.settings().inSliceChunkSize; size != 0 {
+		return size
+	}
+
+	return DefaultInSliceChunkSize
+}
+
+// Ordered is a constraint of types which can be compared by "<", ">", etc.
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 |
+		~string
+}
+
+// InSlice represents "field IN (values...)" for a typed slice.
+// Unlike `Cond#In` with `Flatten`, it doesn't use reflection.
+//
+// If values is longer than `Flavor#InSliceChunkSize`, it's split into "(field IN (...) OR field IN (...))".
+// The chunks are made with the flavor which the builder is built with.
+// If values is empty, the expression is rendered according to `EmptyListPolicy` of the flavor.
+func InSlice[T any](c *Cond, field string, values []T) string {
+	return inSlice(c, field, values, false)
+}
+
+// NotInSlice represents "field NOT IN (values...)" for a typed slice.
+// Unlike `Cond#NotIn` with `Flatten`, it doesn't use reflection.
+//
+// If values is longer than `Flavor#InSliceChunkSize`, it's split into "(field NOT IN (...) AND field NOT IN (...))".
+// The chunks are made with the flavor which the builder is built with.
+// If values is empty, the expression is rendered according to `EmptyListPolicy` of the flavor.
+func NotInSlice[T any](c *Cond, field string, values []T) string {
+	return inSlice(c, field, values, true)
+}
+
+// BetweenOf represents "field BETWEEN lower AND upper" with typed bounds.
+func BetweenOf[T Ordered](c *Cond, field string, lower, upper T) string {
+	return c.Between(field, lower, upper)
+}
+
+// NotBetweenOf represents "field NOT BETWEEN lower AND upper" with typed bounds.
+func NotBetweenOf[T Ordered](c *Cond, field string, lower, upper T) string {
+	return c.NotBetween(field, lower, upper)
+}
+
+func inSlice[T any](c *Cond, field string, values []T, not bool) string {
+	if len(values) == 0 {
+		if not {
+			return c.NotIn(field)
+		}
+
+		return c.In(field)
+	}
+
+	vs := make([]interface{}, 0, len(values))
+
+	for _, v := range values {
+		vs = append(vs, v)
+	}
+
+	return c.Args.Add(inSliceArgs{
+		field:  field,
+		values: vs,
+		not:    not,
+	})
+}
+
+// inSliceArgs is a placeholder of "field IN (values...)" built by `InSlice` or `NotInSlice`.
+// It's split into chunks by the settings of the flavor when the builder is compiled.
+type inSliceArgs struct {
+	field  string
+	values []interface{}
+	not    bool
+}
+
+// writeTo writes the chunks to buf and returns values with the compiled values of e appended.
+func (e inSliceArgs) writeTo(args *Args, buf *strings.Builder, flavor Flavor, values []interface{}) []interface{} {
+	size := flavor.InSliceChunkSize()
+
+	if size <= 0 || size > len(e.values) {
+		size = len(e.values)
+	}
+
+	op := " IN ("
+	join := " OR "
+
+	if e.not {
+		op = " NOT IN ("
+		join = " AND "
+	}
+
+	chunked := size < len(e.values)
+
+	if chunked {
+		buf.WriteString("(")
+	}
+
+	for start := 0; start < len(e.values); start += size {
+		end := start + size
+
+		if end > len(e.values) {
+			end = len(e.values)
+		}
+
+		if start > 0 {
+			buf.WriteString(join)
+		}
+
+		// The field is written to the compiled SQL, so it must not be escaped.
+		buf.WriteString(e.field)
+		buf.WriteString(op)
+
+		for i := start; i < end; i++ {
+			if i > start {
+				buf.WriteString(", ")
+			}
+
+			values = args.compileArg(buf, flavor, values, e.values[i])
+		}
+
+		buf.WriteString(")")
+	}
+
+	if chunked {
+		buf.WriteString(")")
+	}
+
+	return values
+}
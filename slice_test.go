@@ -0,0 +1,99 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func ExampleInSlice() {
+	ids := []int64{1, 2, 3}
+	salary := []uint32{100000, 200000}
+
+	sb := NewSelectBuilder()
+	sb.Select("id").From("vacancies")
+	sb.Where(
+		InSlice(&sb.Cond, "id", ids),
+		NotInSlice(&sb.Cond, "city_id", []string(nil)),
+		BetweenOf(&sb.Cond, "salary", salary[0], salary[1]),
+	)
+
+	s, args := sb.Build()
+	fmt.Println(s)
+	fmt.Println(args)
+
+	// Output:
+	// SELECT id FROM vacancies WHERE id IN (?, ?, ?) AND 1=1 AND salary BETWEEN ? AND ?
+	// [1 2 3 100000 200000]
+}
+
+type testStatus uint8
+
+func TestInSlice(t *testing.T) {
+	a := assert.New(t)
+	cases := map[string]func(c *Cond) string{
+		"a IN (?, ?)":                func(c *Cond) string { return InSlice(c, "a", []int{1, 2}) },
+		"a NOT IN (?)":               func(c *Cond) string { return NotInSlice(c, "a", []string{"x"}) },
+		"b IN (?, ?)":                func(c *Cond) string { return InSlice(c, "b", []testStatus{1, 2}) },
+		"a$b IN (?)":                 func(c *Cond) string { return InSlice(c, "a$b", []int{1}) },
+		"1=0":                        func(c *Cond) string { return InSlice(c, "a", []int{}) },
+		"$a BETWEEN ? AND ?":         func(c *Cond) string { return BetweenOf(c, "$a", 1.5, 2.5) },
+		"a NOT BETWEEN ? AND ?":      func(c *Cond) string { return NotBetweenOf(c, "a", "a", "b") },
+		"a IN (SELECT id FROM t, ?)": func(c *Cond) string { return InSlice(c, "a", []interface{}{Select("id").From("t"), 1}) },
+	}
+
+	for expected, f := range cases {
+		c := newTestCond()
+		actual, _ := c.Args.CompileWithFlavor(f(c), DefaultFlavor)
+		a.Use(&expected, &actual)
+		a.Equal(actual, expected)
+	}
+}
+
+func setTestInSliceChunkSize(t *testing.T, flavor Flavor, size int) {
+	old := flavor.settings().inSliceChunkSize
+	flavor.SetInSliceChunkSize(size)
+	t.Cleanup(func() {
+		flavor.SetInSliceChunkSize(old)
+	})
+}
+
+func TestInSliceChunks(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(DefaultFlavor.InSliceChunkSize(), DefaultInSliceChunkSize)
+	setTestInSliceChunkSize(t, DefaultFlavor, 2)
+
+	sb := NewSelectBuilder()
+	sb.Select("id").From("vacancies")
+	sb.Where(
+		InSlice(&sb.Cond, "id", []int{1, 2, 3, 4, 5}),
+		NotInSlice(&sb.Cond, "city_id", []int{6, 7, 8}),
+		InSlice(&sb.Cond, "company_id", []int{9, 10}),
+	)
+
+	s, args := sb.Build()
+	a.Equal(s, "SELECT id FROM vacancies WHERE (id IN (?, ?) OR id IN (?, ?) OR id IN (?)) AND (city_id NOT IN (?, ?) AND city_id NOT IN (?)) AND company_id IN (?, ?)")
+	a.Equal(args, []interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+
+	// Chunks are made by the flavor which the builder is built with.
+	flavor := Flavor(101)
+	flavor.SetInSliceChunkSize(-1)
+	a.Equal(flavor.InSliceChunkSize(), -1)
+
+	sb = NewSelectBuilder()
+	sb.Select("id").From("vacancies").Where(InSlice(&sb.Cond, "id", []int{1, 2, 3}))
+
+	s, _ = sb.BuildWithFlavor(flavor)
+	a.Equal(s, "SELECT id FROM vacancies WHERE id IN (?, ?, ?)")
+
+	s, _ = sb.Build()
+	a.Equal(s, "SELECT id FROM vacancies WHERE (id IN (?, ?) OR id IN (?))")
+
+	setTestInSliceChunkSize(t, DefaultFlavor, 0)
+	a.Equal(DefaultFlavor.InSliceChunkSize(), DefaultInSliceChunkSize)
+}
@@ -0,0 +1,196 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SnippetOptions represents the options of SNIPPET.
+// Zero fields are omitted, so the server defaults are used for them.
+type SnippetOptions struct {
+	BeforeMatch     string
+	AfterMatch      string
+	ChunkSeparator  string
+	HTMLStripMode   string
+	PassageBoundary string
+	Limit           int
+	Around          int
+	LimitPassages   int
+	LimitWords      int
+	StartPassageID  int
+	ExactPhrase     bool
+	UseBoundaries   bool
+	WeightOrder     bool
+	QueryMode       bool
+	ForceAllWords   bool
+	LoadFiles       bool
+	AllowEmpty      bool
+	EmitZones       bool
+	ForcePassages   bool
+}
+
+func (o SnippetOptions) options() snippetOptionList {
+	var l snippetOptionList
+	l.addString("before_match", o.BeforeMatch)
+	l.addString("after_match", o.AfterMatch)
+	l.addString("chunk_separator", o.ChunkSeparator)
+	l.addString("html_strip_mode", o.HTMLStripMode)
+	l.addString("passage_boundary", o.PassageBoundary)
+	l.addInt("limit", o.Limit)
+	l.addInt("around", o.Around)
+	l.addInt("limit_passages", o.LimitPassages)
+	l.addInt("limit_words", o.LimitWords)
+	l.addInt("start_passage_id", o.StartPassageID)
+	l.addBool("exact_phrase", o.ExactPhrase)
+	l.addBool("use_boundaries", o.UseBoundaries)
+	l.addBool("weight_order", o.WeightOrder)
+	l.addBool("query_mode", o.QueryMode)
+	l.addBool("force_all_words", o.ForceAllWords)
+	l.addBool("load_files", o.LoadFiles)
+	l.addBool("allow_empty", o.AllowEmpty)
+	l.addBool("emit_zones", o.EmitZones)
+	l.addBool("force_passages", o.ForcePassages)
+	return l
+}
+
+// HighlightOptions represents the options map of HIGHLIGHT.
+// Zero fields are omitted, so the server defaults are used for them.
+type HighlightOptions struct {
+	BeforeMatch      string
+	AfterMatch       string
+	SnippetSeparator string
+	FieldSeparator   string
+	HTMLStripMode    string
+	SnippetBoundary  string
+	Limit            int
+	Around           int
+	LimitSnippets    int
+	LimitWords       int
+	StartSnippetID   int
+	LimitsPerField   bool
+	UseBoundaries    bool
+	WeightOrder      bool
+	ForceAllWords    bool
+	AllowEmpty       bool
+	EmitZones        bool
+	ForceSnippets    bool
+}
+
+func (o HighlightOptions) options() snippetOptionList {
+	var l snippetOptionList
+	l.addString("before_match", o.BeforeMatch)
+	l.addString("after_match", o.AfterMatch)
+	l.addString("snippet_separator", o.SnippetSeparator)
+	l.addString("field_separator", o.FieldSeparator)
+	l.addString("html_strip_mode", o.HTMLStripMode)
+	l.addString("snippet_boundary", o.SnippetBoundary)
+	l.addInt("limit", o.Limit)
+	l.addInt("around", o.Around)
+	l.addInt("limit_snippets", o.LimitSnippets)
+	l.addInt("limit_words", o.LimitWords)
+	l.addInt("start_snippet_id", o.StartSnippetID)
+	l.addBool("limits_per_field", o.LimitsPerField)
+	l.addBool("use_boundaries", o.UseBoundaries)
+	l.addBool("weight_order", o.WeightOrder)
+	l.addBool("force_all_words", o.ForceAllWords)
+	l.addBool("allow_empty", o.AllowEmpty)
+	l.addBool("emit_zones", o.EmitZones)
+	l.addBool("force_snippets", o.ForceSnippets)
+	return l
+}
+
+type snippetOption struct {
+	name   string
+	value  string
+	quoted bool
+}
+
+type snippetOptionList []snippetOption
+
+func (l *snippetOptionList) addString(name, value string) {
+	if value != "" {
+		*l = append(*l, snippetOption{name: name, value: value, quoted: true})
+	}
+}
+
+func (l *snippetOptionList) addInt(name string, value int) {
+	if value != 0 {
+		*l = append(*l, snippetOption{name: name, value: strconv.Itoa(value)})
+	}
+}
+
+func (l *snippetOptionList) addBool(name string, value bool) {
+	if value {
+		*l = append(*l, snippetOption{name: name, value: "1"})
+	}
+}
+
+// Snippet represents "SNIPPET(field, query, 'option=value', ...)".
+// The query is added as an arg, so a raw expression can be passed by `Raw`,
+// e.g. `sb.Snippet("content", Raw("QUERY()"), opts)`.
+func (sb *SelectBuilder) Snippet(field string, query interface{}, opts SnippetOptions) string {
+	flavor := sb.quoteFlavor()
+	buf := &strings.Builder{}
+	buf.WriteString("SNIPPET(")
+	buf.WriteString(Escape(field))
+	buf.WriteString(", ")
+	buf.WriteString(sb.args.Add(query))
+
+	for _, opt := range opts.options() {
+		buf.WriteString(", ")
+		buf.WriteString(Escape(flavor.QuoteString(opt.name + "=" + opt.value)))
+	}
+
+	buf.WriteString(")")
+	return buf.String()
+}
+
+// Highlight represents "HIGHLIGHT({option=value, ...}, 'field1,field2')".
+// All fields are highlighted if no field is provided.
+func (sb *SelectBuilder) Highlight(opts HighlightOptions, field ...string) string {
+	flavor := sb.quoteFlavor()
+	options := opts.options()
+	buf := &strings.Builder{}
+	buf.WriteString("HIGHLIGHT(")
+
+	if len(options) > 0 || len(field) > 0 {
+		buf.WriteString("{")
+
+		for i, opt := range options {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+
+			buf.WriteString(opt.name)
+			buf.WriteString("=")
+
+			if opt.quoted {
+				buf.WriteString(Escape(flavor.QuoteString(opt.value)))
+			} else {
+				buf.WriteString(opt.value)
+			}
+		}
+
+		buf.WriteString("}")
+	}
+
+	if len(field) > 0 {
+		buf.WriteString(", ")
+		buf.WriteString(Escape(flavor.QuoteString(strings.Join(field, ","))))
+	}
+
+	buf.WriteString(")")
+	return buf.String()
+}
+
+func (sb *SelectBuilder) quoteFlavor() Flavor {
+	if sb.args.Flavor == invalidFlavor {
+		return DefaultFlavor
+	}
+
+	return sb.args.Flavor
+}
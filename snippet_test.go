@@ -0,0 +1,85 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func ExampleSelectBuilder_Snippet() {
+	sb := NewSelectBuilder()
+	sb.Select("id", sb.As(sb.Snippet("content", Raw("QUERY()"), SnippetOptions{
+		BeforeMatch: "<b>",
+		AfterMatch:  "</b>",
+		Limit:       100,
+	}), "snippet"))
+	sb.From("vacancies")
+	sb.Where(sb.Match("golang"))
+
+	s, args := sb.Build()
+	fmt.Println(s)
+	fmt.Println(args)
+
+	// Output:
+	// SELECT id, SNIPPET(content, QUERY(), 'before_match=<b>', 'after_match=</b>', 'limit=100') AS snippet FROM vacancies WHERE MATCH(?)
+	// [golang]
+}
+
+func ExampleSelectBuilder_Highlight() {
+	sb := NewSelectBuilder()
+	sb.Select("id", sb.Highlight(HighlightOptions{
+		Limit:       100,
+		BeforeMatch: "<b>",
+	}, "title", "body"))
+	sb.From("vacancies")
+	sb.Where(sb.Match("golang"))
+
+	s, args := sb.Build()
+	fmt.Println(s)
+	fmt.Println(args)
+
+	// Output:
+	// SELECT id, HIGHLIGHT({before_match='<b>', limit=100}, 'title,body') FROM vacancies WHERE MATCH(?)
+	// [golang]
+}
+
+func TestSnippet(t *testing.T) {
+	a := assert.New(t)
+	cases := map[string]func() string{
+		"SNIPPET(content, $0)": func() string {
+			return newSelectBuilder().Snippet("content", "query", SnippetOptions{})
+		},
+		`SNIPPET(content, $0, 'before_match=<a href=\'$$x\'>', 'chunk_separator=\\\\', 'exact_phrase=1', 'allow_empty=1')`: func() string {
+			return newSelectBuilder().Snippet("content", "query", SnippetOptions{
+				BeforeMatch:    "<a href='$x'>",
+				ChunkSeparator: `\\`,
+				ExactPhrase:    true,
+				AllowEmpty:     true,
+			})
+		},
+		"HIGHLIGHT()": func() string {
+			return newSelectBuilder().Highlight(HighlightOptions{})
+		},
+		"HIGHLIGHT({}, 'title')": func() string {
+			return newSelectBuilder().Highlight(HighlightOptions{}, "title")
+		},
+		`HIGHLIGHT({before_match='<b class="hl">', snippet_separator='\'...\'', around=3, force_snippets=1})`: func() string {
+			return newSelectBuilder().Highlight(HighlightOptions{
+				BeforeMatch:      `<b class="hl">`,
+				SnippetSeparator: "'...'",
+				Around:           3,
+				ForceSnippets:    true,
+			})
+		},
+	}
+
+	for expected, f := range cases {
+		actual := f()
+		a.Equal(actual, expected)
+	}
+}
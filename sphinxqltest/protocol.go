@@ -0,0 +1,309 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxqltest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Commands of the MySQL protocol handled by the server.
+const (
+	comQuit        = 0x01
+	comInitDB      = 0x02
+	comQuery       = 0x03
+	comPing        = 0x0e
+	comStmtPrepare = 0x16
+)
+
+// Capability flags announced by the server.
+// CLIENT_DEPRECATE_EOF is not announced, so that result sets always end with EOF packets.
+const (
+	clientLongPassword     = 0x00000001
+	clientLongFlag         = 0x00000004
+	clientConnectWithDB    = 0x00000008
+	clientProtocol41       = 0x00000200
+	clientTransactions     = 0x00002000
+	clientSecureConnection = 0x00008000
+	clientPluginAuth       = 0x00080000
+
+	serverCapabilities = clientLongPassword | clientLongFlag | clientConnectWithDB | clientProtocol41 |
+		clientTransactions | clientSecureConnection | clientPluginAuth
+)
+
+// Column types of the MySQL protocol.
+const (
+	typeFloat     = 0x04
+	typeDouble    = 0x05
+	typeLongLong  = 0x08
+	typeVarString = 0xfd
+)
+
+const (
+	charsetUTF8      = 33
+	statusAutocommit = 0x0002
+	maxPacketSize    = 1<<24 - 1
+)
+
+var errMalformedPacket = errors.New("go-sphinxql: malformed packet")
+
+// packetConn reads and writes packets of the MySQL protocol.
+type packetConn struct {
+	r   *bufio.Reader
+	w   *bufio.Writer
+	seq byte
+}
+
+func newPacketConn(rw io.ReadWriter) *packetConn {
+	return &packetConn{
+		r: bufio.NewReader(rw),
+		w: bufio.NewWriter(rw),
+	}
+}
+
+// readPacket reads a payload, which may be split into several packets.
+func (pc *packetConn) readPacket() ([]byte, error) {
+	var payload []byte
+	header := make([]byte, 4)
+
+	for {
+		if _, err := io.ReadFull(pc.r, header); err != nil {
+			return nil, err
+		}
+
+		size := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+		pc.seq = header[3] + 1
+		start := len(payload)
+		payload = append(payload, make([]byte, size)...)
+
+		if _, err := io.ReadFull(pc.r, payload[start:]); err != nil {
+			return nil, err
+		}
+
+		if size < maxPacketSize {
+			return payload, nil
+		}
+	}
+}
+
+// writePacket writes a payload, splitting it into packets if it's too large.
+// The payload is buffered until flush is called.
+func (pc *packetConn) writePacket(payload []byte) error {
+	for {
+		size := len(payload)
+
+		if size > maxPacketSize {
+			size = maxPacketSize
+		}
+
+		header := []byte{byte(size), byte(size >> 8), byte(size >> 16), pc.seq}
+		pc.seq++
+
+		if _, err := pc.w.Write(header); err != nil {
+			return err
+		}
+
+		if _, err := pc.w.Write(payload[:size]); err != nil {
+			return err
+		}
+
+		payload = payload[size:]
+
+		// A payload of exactly maxPacketSize bytes is followed by an empty packet.
+		if size < maxPacketSize {
+			return nil
+		}
+	}
+}
+
+func (pc *packetConn) flush() error {
+	return pc.w.Flush()
+}
+
+func (pc *packetConn) writeHandshake(connID uint32, version string, salt []byte) error {
+	buf := []byte{10}
+	buf = append(buf, version...)
+	buf = append(buf, 0)
+	buf = appendUint32(buf, connID)
+	buf = append(buf, salt[:8]...)
+	buf = append(buf, 0)
+	buf = appendUint16(buf, uint16(serverCapabilities&0xffff))
+	buf = append(buf, charsetUTF8)
+	buf = appendUint16(buf, statusAutocommit)
+	buf = appendUint16(buf, uint16(serverCapabilities>>16))
+	buf = append(buf, byte(len(salt)+1))
+	buf = append(buf, make([]byte, 10)...)
+	buf = append(buf, salt[8:]...)
+	buf = append(buf, 0)
+	buf = append(buf, "mysql_native_password"...)
+	buf = append(buf, 0)
+	return pc.writePacket(buf)
+}
+
+func (pc *packetConn) writeOK(affectedRows, lastInsertID uint64) error {
+	buf := []byte{0x00}
+	buf = appendLengthEncodedInt(buf, affectedRows)
+	buf = appendLengthEncodedInt(buf, lastInsertID)
+	buf = appendUint16(buf, statusAutocommit)
+	buf = appendUint16(buf, 0)
+	return pc.writePacket(buf)
+}
+
+func (pc *packetConn) writeEOF() error {
+	buf := []byte{0xfe}
+	buf = appendUint16(buf, 0)
+	buf = appendUint16(buf, statusAutocommit)
+	return pc.writePacket(buf)
+}
+
+func (pc *packetConn) writeError(err *Error) error {
+	code := err.Code
+
+	if code == 0 {
+		code = ErrCodeParse
+	}
+
+	buf := []byte{0xff}
+	buf = appendUint16(buf, code)
+	buf = append(buf, "#42000"...)
+	buf = append(buf, err.Message...)
+	return pc.writePacket(buf)
+}
+
+// writeResultSet writes columns and rows in the text protocol.
+func (pc *packetConn) writeResultSet(columns []string, rows [][]interface{}) error {
+	if err := pc.writePacket(appendLengthEncodedInt(nil, uint64(len(columns)))); err != nil {
+		return err
+	}
+
+	for i, name := range columns {
+		if err := pc.writePacket(appendColumnDefinition(nil, name, columnType(rows, i))); err != nil {
+			return err
+		}
+	}
+
+	if err := pc.writeEOF(); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		buf := []byte{}
+
+		for i := range columns {
+			var value interface{}
+
+			if i < len(row) {
+				value = row[i]
+			}
+
+			if value == nil {
+				buf = append(buf, 0xfb)
+				continue
+			}
+
+			buf = appendLengthEncodedString(buf, formatValue(value))
+		}
+
+		if err := pc.writePacket(buf); err != nil {
+			return err
+		}
+	}
+
+	return pc.writeEOF()
+}
+
+func appendColumnDefinition(buf []byte, name string, typ byte) []byte {
+	buf = appendLengthEncodedString(buf, "def")
+	buf = appendLengthEncodedString(buf, "")
+	buf = appendLengthEncodedString(buf, "")
+	buf = appendLengthEncodedString(buf, "")
+	buf = appendLengthEncodedString(buf, name)
+	buf = appendLengthEncodedString(buf, name)
+	buf = append(buf, 0x0c)
+	buf = appendUint16(buf, charsetUTF8)
+	buf = appendUint32(buf, 255)
+	buf = append(buf, typ)
+	buf = appendUint16(buf, 0)
+	buf = append(buf, 0)
+	buf = appendUint16(buf, 0)
+	return buf
+}
+
+// columnType returns the type of a column by the first non-nil value in it.
+func columnType(rows [][]interface{}, col int) byte {
+	for _, row := range rows {
+		if col >= len(row) || row[col] == nil {
+			continue
+		}
+
+		switch row[col].(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, bool:
+			return typeLongLong
+		case float32:
+			return typeFloat
+		case float64:
+			return typeDouble
+		}
+
+		return typeVarString
+	}
+
+	return typeVarString
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case bool:
+		if v {
+			return "1"
+		}
+
+		return "0"
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
+
+func appendLengthEncodedInt(buf []byte, n uint64) []byte {
+	switch {
+	case n < 251:
+		return append(buf, byte(n))
+	case n < 1<<16:
+		return append(buf, 0xfc, byte(n), byte(n>>8))
+	case n < 1<<24:
+		return append(buf, 0xfd, byte(n), byte(n>>8), byte(n>>16))
+	}
+
+	buf = append(buf, 0xfe)
+	return appendUint64(buf, n)
+}
+
+func appendLengthEncodedString(buf []byte, s string) []byte {
+	buf = appendLengthEncodedInt(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func appendUint16(buf []byte, n uint16) []byte {
+	return append(buf, byte(n), byte(n>>8))
+}
+
+func appendUint32(buf []byte, n uint32) []byte {
+	return append(buf, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+}
+
+func appendUint64(buf []byte, n uint64) []byte {
+	return appendUint32(appendUint32(buf, uint32(n)), uint32(n>>32))
+}
@@ -0,0 +1,291 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxqltest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+// testClient is a minimal MySQL client written from the protocol docs.
+// It doesn't share packet handling with the server, so that both sides are checked.
+type testClient struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	seq     byte
+	version string
+}
+
+type testResult struct {
+	columns      []string
+	types        []byte
+	rows         [][]interface{}
+	affectedRows uint64
+	lastInsertID uint64
+}
+
+func dialTestClient(addr string) (*testClient, error) {
+	conn, err := net.Dial("tcp", addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c := &testClient{conn: conn, r: bufio.NewReader(conn)}
+	handshake, err := c.readPacket()
+
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if handshake[0] != 10 {
+		conn.Close()
+		return nil, errors.New("unexpected protocol version")
+	}
+
+	end := bytes.IndexByte(handshake[1:], 0)
+	c.version = string(handshake[1 : 1+end])
+
+	// Capabilities are right after version, connection id, salt and filler.
+	caps := binary.LittleEndian.Uint16(handshake[1+end+1+4+8+1:])
+
+	if caps&clientProtocol41 == 0 {
+		conn.Close()
+		return nil, errors.New("protocol 4.1 is not supported")
+	}
+
+	resp := make([]byte, 0, 64)
+	resp = appendUint32(resp, clientProtocol41|clientSecureConnection|clientPluginAuth)
+	resp = appendUint32(resp, 1<<24)
+	resp = append(resp, 33)
+	resp = append(resp, make([]byte, 23)...)
+	resp = append(resp, "root"...)
+	resp = append(resp, 0, 0)
+	resp = append(resp, "mysql_native_password"...)
+	resp = append(resp, 0)
+
+	if err := c.writePacket(resp); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if _, err := c.readResult(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *testClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *testClient) readPacket() ([]byte, error) {
+	header := make([]byte, 4)
+
+	if _, err := io.ReadFull(c.r, header); err != nil {
+		return nil, err
+	}
+
+	if header[3] != c.seq {
+		return nil, errors.New("unexpected sequence id")
+	}
+
+	c.seq++
+	payload := make([]byte, int(header[0])|int(header[1])<<8|int(header[2])<<16)
+	_, err := io.ReadFull(c.r, payload)
+	return payload, err
+}
+
+func (c *testClient) writePacket(payload []byte) error {
+	header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), c.seq}
+	c.seq++
+	_, err := c.conn.Write(append(header, payload...))
+	return err
+}
+
+func (c *testClient) command(cmd byte, arg string) (*testResult, error) {
+	c.seq = 0
+
+	if err := c.writePacket(append([]byte{cmd}, arg...)); err != nil {
+		return nil, err
+	}
+
+	return c.readResult()
+}
+
+func (c *testClient) query(query string) (*testResult, error) {
+	return c.command(comQuery, query)
+}
+
+func (c *testClient) readResult() (*testResult, error) {
+	payload, err := c.readPacket()
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch payload[0] {
+	case 0x00:
+		res := &testResult{}
+		payload = payload[1:]
+		res.affectedRows, payload = readLengthEncodedInt(payload)
+		res.lastInsertID, _ = readLengthEncodedInt(payload)
+		return res, nil
+	case 0xff:
+		return nil, &Error{
+			Code:    binary.LittleEndian.Uint16(payload[1:]),
+			Message: string(payload[9:]),
+		}
+	}
+
+	n, _ := readLengthEncodedInt(payload)
+	res := &testResult{}
+
+	for i := uint64(0); i < n; i++ {
+		def, err := c.readPacket()
+
+		if err != nil {
+			return nil, err
+		}
+
+		var name string
+
+		// Name follows catalog, schema, table and org_table.
+		for j := 0; j < 5; j++ {
+			name, def = readLengthEncodedString(def)
+		}
+
+		_, def = readLengthEncodedString(def)
+		res.columns = append(res.columns, name)
+		res.types = append(res.types, def[1+2+4])
+	}
+
+	if err := c.readEOF(); err != nil {
+		return nil, err
+	}
+
+	for {
+		payload, err := c.readPacket()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if payload[0] == 0xfe && len(payload) < 9 {
+			return res, nil
+		}
+
+		row := make([]interface{}, 0, n)
+
+		for i := uint64(0); i < n; i++ {
+			if payload[0] == 0xfb {
+				row = append(row, nil)
+				payload = payload[1:]
+				continue
+			}
+
+			var value string
+			value, payload = readLengthEncodedString(payload)
+			row = append(row, value)
+		}
+
+		res.rows = append(res.rows, row)
+	}
+}
+
+func (c *testClient) readEOF() error {
+	payload, err := c.readPacket()
+
+	if err != nil {
+		return err
+	}
+
+	if payload[0] != 0xfe {
+		return errors.New("EOF packet is expected")
+	}
+
+	return nil
+}
+
+func readLengthEncodedInt(b []byte) (uint64, []byte) {
+	switch b[0] {
+	case 0xfc:
+		return uint64(binary.LittleEndian.Uint16(b[1:])), b[3:]
+	case 0xfd:
+		return uint64(b[1]) | uint64(b[2])<<8 | uint64(b[3])<<16, b[4:]
+	case 0xfe:
+		return binary.LittleEndian.Uint64(b[1:]), b[9:]
+	}
+
+	return uint64(b[0]), b[1:]
+}
+
+func readLengthEncodedString(b []byte) (string, []byte) {
+	n, b := readLengthEncodedInt(b)
+	return string(b[:n]), b[n:]
+}
+
+func TestLengthEncodedInt(t *testing.T) {
+	a := assert.New(t)
+
+	for _, n := range []uint64{0, 250, 251, 1<<16 - 1, 1 << 16, 1<<24 - 1, 1 << 24, 1<<64 - 1} {
+		buf := appendLengthEncodedInt(nil, n)
+		actual, rest := readLengthEncodedInt(buf)
+		a.Use(&n)
+		a.Equal(actual, n)
+		a.Equal(len(rest), 0)
+	}
+}
+
+func TestPacketConnLargePayload(t *testing.T) {
+	a := assert.New(t)
+	buf := &bytes.Buffer{}
+	pc := newPacketConn(buf)
+
+	for _, size := range []int{0, 10, maxPacketSize, maxPacketSize + 10} {
+		payload := bytes.Repeat([]byte{'x'}, size)
+		a.Use(&size)
+		a.NilError(pc.writePacket(payload))
+		a.NilError(pc.flush())
+
+		actual, err := pc.readPacket()
+		a.NilError(err)
+		a.Equal(len(actual), size)
+		a.Equal(buf.Len(), 0)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	a := assert.New(t)
+	cases := []struct {
+		value    interface{}
+		expected string
+		typ      byte
+	}{
+		{"go", "go", typeVarString},
+		{[]byte("go"), "go", typeVarString},
+		{int64(-1), "-1", typeLongLong},
+		{uint32(1), "1", typeLongLong},
+		{true, "1", typeLongLong},
+		{float32(0.1), "0.1", typeFloat},
+		{1e21, "1000000000000000000000", typeDouble},
+	}
+
+	for _, c := range cases {
+		a.Use(&c)
+		a.Equal(formatValue(c.value), c.expected)
+		a.Equal(columnType([][]interface{}{{nil}, {c.value}}, 0), c.typ)
+	}
+}
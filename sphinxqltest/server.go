@@ -0,0 +1,488 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+// Package sphinxqltest provides an in-process fake searchd for tests.
+//
+// The Server speaks the MySQL wire protocol like searchd does, so any MySQL driver can connect to it.
+// Tests register canned results for queries and check the queries received by the server.
+//
+//	srv, err := sphinxqltest.NewServer()
+//	defer srv.Close()
+//
+//	srv.Handle("SELECT id FROM vacancies WHERE MATCH(?)", &sphinxqltest.Result{
+//		Columns: []string{"id"},
+//		Rows:    [][]interface{}{{1}, {2}},
+//	})
+//
+//	db, err := sql.Open("mysql", "tcp("+srv.Addr()+")/")
+package sphinxqltest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	sphinxql "github.com/superjobru/go-sphinxql"
+)
+
+// Version is the server version announced to clients.
+const Version = "2.2.11-id64-release (sphinxqltest)"
+
+// ErrCodeParse is the error code which searchd sends for almost all errors.
+const ErrCodeParse = 1064
+
+// Error is an error sent to a client as an ERR packet.
+type Error struct {
+	// Code is the error code. Zero means ErrCodeParse.
+	Code    uint16
+	Message string
+}
+
+// Error returns the message of e.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// MetaVar is a variable reported by `SHOW META`.
+type MetaVar struct {
+	Name  string
+	Value string
+}
+
+// Result is a canned result of a query.
+//
+// If Err is set, the error is sent. Otherwise, if Columns is set, a result set is sent.
+// Otherwise, an OK packet with AffectedRows and LastInsertID is sent.
+// A Result must not be modified after it's registered.
+type Result struct {
+	Columns []string
+	Rows    [][]interface{}
+
+	AffectedRows uint64
+	LastInsertID uint64
+
+	// TotalFound is reported by `SHOW META` after the result set is sent.
+	// Zero means the number of rows.
+	TotalFound uint64
+
+	// Meta is reported by `SHOW META` in addition to total, total_found and time.
+	// A variable with the same name replaces the default one.
+	Meta []MetaVar
+
+	Err *Error
+}
+
+type patternResult struct {
+	pattern *regexp.Regexp
+	result  *Result
+}
+
+// Server is a fake searchd listening on a local socket.
+// It's safe to register results and read queries while clients are connected.
+type Server struct {
+	listener net.Listener
+
+	mu           sync.Mutex
+	fingerprints map[uint64]*Result
+	patterns     []patternResult
+	queries      []string
+	errs         []error
+	conns        map[net.Conn]struct{}
+	lastConnID   uint32
+	closed       bool
+
+	wg sync.WaitGroup
+}
+
+// NewServer starts a Server listening on a random port of the loopback interface.
+func NewServer() (*Server, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewServerWithListener(l), nil
+}
+
+// NewServerWithListener starts a Server accepting connections from l, e.g. a unix socket listener.
+// The l is closed by `Server#Close`.
+func NewServerWithListener(l net.Listener) *Server {
+	s := &Server{
+		listener:     l,
+		fingerprints: map[uint64]*Result{},
+		conns:        map[net.Conn]struct{}{},
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// Addr returns the address of the server, e.g. "127.0.0.1:9306".
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server and closes all client connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.closed = true
+	err := s.listener.Close()
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+// Handle registers result for all queries with the same fingerprint as query.
+// As literals are normalized by `sphinxql.FingerprintSQL`, query can be either
+// a compiled SQL with placeholders or an interpolated one.
+func (s *Server) Handle(query string, result *Result) {
+	_, hash := sphinxql.FingerprintSQL(query)
+	s.HandleFingerprint(hash, result)
+}
+
+// HandleFingerprint registers result for all queries with the fingerprint hash
+// returned by `sphinxql.Fingerprint` or `sphinxql.FingerprintSQL`.
+func (s *Server) HandleFingerprint(hash uint64, result *Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fingerprints[hash] = result
+}
+
+// HandlePattern registers result for all queries matching pattern.
+// Fingerprints are looked up before patterns, and patterns are tried in the order of registration.
+func (s *Server) HandlePattern(pattern *regexp.Regexp, result *Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.patterns = append(s.patterns, patternResult{
+		pattern: pattern,
+		result:  result,
+	})
+}
+
+// Queries returns all queries received by the server in order, including `SHOW META`.
+func (s *Server) Queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.queries...)
+}
+
+// ResetQueries forgets queries received by the server.
+func (s *Server) ResetQueries() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queries = nil
+}
+
+// Errors returns errors which broke client connections in order, e.g. malformed packets.
+// Errors caused by `Server#Close` and clients disconnecting are not recorded,
+// so a test can expect no errors after all clients are done.
+func (s *Server) Errors() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]error(nil), s.errs...)
+}
+
+func (s *Server) addError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || errors.Is(err, io.EOF) {
+		return
+	}
+
+	s.errs = append(s.errs, err)
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+
+		s.lastConnID++
+		connID := s.lastConnID
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			defer s.removeConn(conn)
+
+			if err := s.serveConn(conn, connID); err != nil {
+				s.addError(fmt.Errorf("go-sphinxql: connection %d: %w", connID, err))
+			}
+		}()
+	}
+}
+
+func (s *Server) removeConn(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.conns, conn)
+	conn.Close()
+}
+
+// session is the state of a client connection.
+type session struct {
+	pc   *packetConn
+	meta []MetaVar
+}
+
+func (s *Server) serveConn(conn net.Conn, connID uint32) error {
+	pc := newPacketConn(conn)
+	salt := []byte(fmt.Sprintf("%020d", connID))
+
+	if err := pc.writeHandshake(connID, Version, salt); err != nil {
+		return err
+	}
+
+	if err := pc.flush(); err != nil {
+		return err
+	}
+
+	// Any credentials are accepted.
+	if _, err := pc.readPacket(); err != nil {
+		return err
+	}
+
+	if err := pc.writeOK(0, 0); err != nil {
+		return err
+	}
+
+	if err := pc.flush(); err != nil {
+		return err
+	}
+
+	sess := &session{pc: pc}
+
+	for {
+		payload, err := pc.readPacket()
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if len(payload) == 0 {
+			return errMalformedPacket
+		}
+
+		switch payload[0] {
+		case comQuit:
+			return nil
+		case comInitDB, comPing:
+			err = pc.writeOK(0, 0)
+		case comQuery:
+			err = s.query(sess, string(payload[1:]))
+		case comStmtPrepare:
+			err = pc.writeError(&Error{Message: "prepared statements are not supported"})
+		default:
+			err = pc.writeError(&Error{Message: fmt.Sprintf("unknown command %d", payload[0])})
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := pc.flush(); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) query(sess *session, query string) error {
+	s.mu.Lock()
+	s.queries = append(s.queries, query)
+	s.mu.Unlock()
+
+	if pattern, ok := parseShowMeta(query); ok {
+		return s.showMeta(sess, pattern)
+	}
+
+	result := s.lookup(query)
+
+	if result == nil {
+		if returnsRows(query) {
+			return sess.pc.writeError(&Error{Message: "sphinxqltest: no result registered for query: " + query})
+		}
+
+		return sess.pc.writeOK(0, 0)
+	}
+
+	switch {
+	case result.Err != nil:
+		return sess.pc.writeError(result.Err)
+	case result.Columns != nil:
+		sess.meta = resultMeta(result)
+		return sess.pc.writeResultSet(result.Columns, result.Rows)
+	}
+
+	return sess.pc.writeOK(result.AffectedRows, result.LastInsertID)
+}
+
+func (s *Server) lookup(query string) *Result {
+	_, hash := sphinxql.FingerprintSQL(query)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if result, ok := s.fingerprints[hash]; ok {
+		return result
+	}
+
+	for _, pr := range s.patterns {
+		if pr.pattern.MatchString(query) {
+			return pr.result
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) showMeta(sess *session, pattern *regexp.Regexp) error {
+	rows := make([][]interface{}, 0, len(sess.meta))
+
+	for _, v := range sess.meta {
+		if pattern == nil || pattern.MatchString(v.Name) {
+			rows = append(rows, []interface{}{v.Name, v.Value})
+		}
+	}
+
+	return sess.pc.writeResultSet([]string{"Variable_name", "Value"}, rows)
+}
+
+// resultMeta returns variables of `SHOW META` after result is sent.
+func resultMeta(result *Result) []MetaVar {
+	total := uint64(len(result.Rows))
+	totalFound := result.TotalFound
+
+	if totalFound == 0 {
+		totalFound = total
+	}
+
+	meta := []MetaVar{
+		{Name: "total", Value: strconv.FormatUint(total, 10)},
+		{Name: "total_found", Value: strconv.FormatUint(totalFound, 10)},
+		{Name: "time", Value: "0.000"},
+	}
+
+	for _, v := range result.Meta {
+		replaced := false
+
+		for i := range meta {
+			if meta[i].Name == v.Name {
+				meta[i].Value = v.Value
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			meta = append(meta, v)
+		}
+	}
+
+	return meta
+}
+
+var showMetaRegexp = regexp.MustCompile(`(?is)^\s*SHOW\s+META(?:\s+LIKE\s+'((?:[^'\\]|\\.)*)')?\s*;?\s*$`)
+
+// parseShowMeta parses `SHOW META [LIKE 'pattern']`.
+// The pattern is converted to a regexp, which is nil if there is no LIKE.
+func parseShowMeta(query string) (pattern *regexp.Regexp, ok bool) {
+	m := showMetaRegexp.FindStringSubmatchIndex(query)
+
+	if m == nil {
+		return nil, false
+	}
+
+	if m[2] < 0 {
+		return nil, true
+	}
+
+	return likeRegexp(query[m[2]:m[3]]), true
+}
+
+// likeRegexp converts a LIKE pattern to a case-insensitive regexp.
+func likeRegexp(like string) *regexp.Regexp {
+	buf := &strings.Builder{}
+	buf.WriteString("(?is)^")
+
+	escaped := false
+
+	for _, r := range like {
+		switch {
+		case escaped:
+			escaped = false
+			buf.WriteString(regexp.QuoteMeta(string(r)))
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			buf.WriteString(".*")
+		case r == '_':
+			buf.WriteString(".")
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	buf.WriteString("$")
+	return regexp.MustCompile(buf.String())
+}
+
+// returnsRows reports whether a client expects a result set for query.
+func returnsRows(query string) bool {
+	fields := strings.Fields(query)
+
+	if len(fields) == 0 {
+		return false
+	}
+
+	switch strings.ToUpper(strings.TrimLeft(fields[0], "(")) {
+	case "SELECT", "SHOW", "DESC", "DESCRIBE", "CALL":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,339 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxqltest
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/huandu/go-assert"
+	sphinxql "github.com/superjobru/go-sphinxql"
+)
+
+func TestServer(t *testing.T) {
+	a := assert.New(t)
+	srv, err := NewServer()
+	a.NilError(err)
+	defer srv.Close()
+
+	sb := sphinxql.SphinxSearch.NewSelectBuilder()
+	sb.Select("id", "title", "salary", "company_id").From("vacancies")
+	sb.Where(sb.Match("golang"), sb.In("city_id", 1, 2))
+	sb.Limit(10)
+
+	srv.Handle(sb.String(), &Result{
+		Columns: []string{"id", "title", "salary", "company_id"},
+		Rows: [][]interface{}{
+			{1, "Go developer", 1.5, nil},
+			{2, "Senior Go developer", 2.25, 10},
+		},
+		TotalFound: 100,
+		Meta: []MetaVar{
+			{Name: "keyword[0]", Value: "golang"},
+			{Name: "time", Value: "0.001"},
+		},
+	})
+	srv.HandlePattern(regexp.MustCompile(`(?i)^UPDATE vacancies\b`), &Result{AffectedRows: 3})
+	srv.HandlePattern(regexp.MustCompile(`(?i)^SELECT .* FROM broken\b`), &Result{
+		Err: &Error{Code: 1064, Message: "index broken: no such index"},
+	})
+
+	c, err := dialTestClient(srv.Addr())
+	a.NilError(err)
+	defer c.Close()
+	a.Equal(c.version, Version)
+
+	// Queries with the same fingerprint get the same result.
+	sb = sphinxql.SphinxSearch.NewSelectBuilder()
+	sb.Select("id", "title", "salary", "company_id").From("vacancies")
+	sb.Where(sb.Match("python"), sb.In("city_id", 3, 4, 5))
+	sb.Limit(20)
+	query, err := sb.BuildInterpolated()
+	a.NilError(err)
+
+	res, err := c.query(query)
+	a.NilError(err)
+	a.Equal(res.columns, []string{"id", "title", "salary", "company_id"})
+	a.Equal(res.types, []byte{typeLongLong, typeVarString, typeDouble, typeLongLong})
+	a.Equal(res.rows, [][]interface{}{
+		{"1", "Go developer", "1.5", nil},
+		{"2", "Senior Go developer", "2.25", "10"},
+	})
+
+	res, err = c.query("SHOW META")
+	a.NilError(err)
+	a.Equal(res.columns, []string{"Variable_name", "Value"})
+	a.Equal(res.rows, [][]interface{}{
+		{"total", "2"},
+		{"total_found", "100"},
+		{"time", "0.001"},
+		{"keyword[0]", "golang"},
+	})
+
+	res, err = c.query("show meta like 'TOTAL%'")
+	a.NilError(err)
+	a.Equal(res.rows, [][]interface{}{
+		{"total", "2"},
+		{"total_found", "100"},
+	})
+
+	res, err = c.query(`SHOW META LIKE 'keyword\_0_'`)
+	a.NilError(err)
+	a.Equal(len(res.rows), 0)
+
+	res, err = c.query("UPDATE vacancies SET salary = 1 WHERE id = 1")
+	a.NilError(err)
+	a.Equal(res.affectedRows, uint64(3))
+
+	_, err = c.query("SELECT id FROM broken")
+	a.Equal(err, &Error{Code: 1064, Message: "index broken: no such index"})
+
+	// Unregistered queries.
+	res, err = c.query("INSERT INTO vacancies (id) VALUES (1)")
+	a.NilError(err)
+	a.Equal(res.affectedRows, uint64(0))
+
+	_, err = c.query("SELECT id FROM resumes")
+	var e *Error
+	a.Assert(errors.As(err, &e))
+	a.Equal(e.Code, uint16(ErrCodeParse))
+
+	// SHOW META still reports the last result set.
+	res, err = c.query("SHOW META LIKE 'total'")
+	a.NilError(err)
+	a.Equal(res.rows, [][]interface{}{{"total", "2"}})
+
+	_, err = c.command(comPing, "")
+	a.NilError(err)
+
+	_, err = c.command(comStmtPrepare, "SELECT 1")
+	a.Equal(err, &Error{Code: ErrCodeParse, Message: "prepared statements are not supported"})
+
+	a.Equal(srv.Queries(), []string{
+		query,
+		"SHOW META",
+		"show meta like 'TOTAL%'",
+		`SHOW META LIKE 'keyword\_0_'`,
+		"UPDATE vacancies SET salary = 1 WHERE id = 1",
+		"SELECT id FROM broken",
+		"INSERT INTO vacancies (id) VALUES (1)",
+		"SELECT id FROM resumes",
+		"SHOW META LIKE 'total'",
+	})
+
+	srv.ResetQueries()
+	a.Equal(len(srv.Queries()), 0)
+}
+
+func TestServerSessions(t *testing.T) {
+	a := assert.New(t)
+	srv, err := NewServer()
+	a.NilError(err)
+	defer srv.Close()
+
+	_, hash := sphinxql.FingerprintSQL("SELECT id FROM idx")
+	srv.HandleFingerprint(hash, &Result{
+		Columns: []string{"id"},
+		Rows:    [][]interface{}{{1}},
+	})
+
+	c1, err := dialTestClient(srv.Addr())
+	a.NilError(err)
+	defer c1.Close()
+
+	c2, err := dialTestClient(srv.Addr())
+	a.NilError(err)
+	defer c2.Close()
+
+	_, err = c1.query("SELECT id FROM idx")
+	a.NilError(err)
+
+	// SHOW META is per connection.
+	res, err := c1.query("SHOW META LIKE 'total'")
+	a.NilError(err)
+	a.Equal(res.rows, [][]interface{}{{"total", "1"}})
+
+	res, err = c2.query("SHOW META")
+	a.NilError(err)
+	a.Equal(len(res.rows), 0)
+}
+
+func TestServerMySQLDriver(t *testing.T) {
+	a := assert.New(t)
+	srv, err := NewServer()
+	a.NilError(err)
+	defer srv.Close()
+
+	srv.Handle("SELECT id, title FROM vacancies WHERE MATCH(?) LIMIT ?", &Result{
+		Columns:    []string{"id", "title"},
+		Rows:       [][]interface{}{{1, "Go developer"}, {2, "Senior Go developer"}},
+		TotalFound: 100,
+	})
+	srv.HandlePattern(regexp.MustCompile(`^UPDATE vacancies\b`), &Result{AffectedRows: 2})
+
+	cfg := mysql.NewConfig()
+	cfg.Net = "tcp"
+	cfg.Addr = srv.Addr()
+	connector, err := mysql.NewConnector(cfg)
+	a.NilError(err)
+
+	db := sql.OpenDB(sphinxql.NewConnector(connector, sphinxql.SphinxSearch))
+	defer db.Close()
+	a.NilError(db.Ping())
+
+	sb := sphinxql.SphinxSearch.NewSelectBuilder()
+	sb.Select("id", "title").From("vacancies").Where(sb.Match("golang")).Limit(10)
+	query, args := sb.Build()
+
+	// A single connection is used, as SHOW META reports the last query of the connection.
+	conn, err := db.Conn(context.Background())
+	a.NilError(err)
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(context.Background(), query, args...)
+	a.NilError(err)
+
+	var ids []int64
+	var titles []string
+
+	for rows.Next() {
+		var id int64
+		var title string
+		a.NilError(rows.Scan(&id, &title))
+		ids = append(ids, id)
+		titles = append(titles, title)
+	}
+
+	a.NilError(rows.Err())
+	a.NilError(rows.Close())
+	a.Equal(ids, []int64{1, 2})
+	a.Equal(titles, []string{"Go developer", "Senior Go developer"})
+
+	var name, value string
+	a.NilError(conn.QueryRowContext(context.Background(), "SHOW META LIKE 'total_found'").Scan(&name, &value))
+	a.Equal(value, "100")
+
+	res, err := conn.ExecContext(context.Background(), "UPDATE vacancies SET salary = ? WHERE id IN (?, ?)", 1.5, 1, 2)
+	a.NilError(err)
+	affected, err := res.RowsAffected()
+	a.NilError(err)
+	a.Equal(affected, int64(2))
+
+	_, err = conn.ExecContext(context.Background(), "DELETE FROM vacancies WHERE id = ?", 3)
+	a.NilError(err)
+
+	_, err = conn.QueryContext(context.Background(), "SELECT id FROM resumes")
+	var e *mysql.MySQLError
+	a.Assert(errors.As(err, &e))
+	a.Equal(e.Number, uint16(ErrCodeParse))
+
+	a.Equal(srv.Queries(), []string{
+		"SELECT id, title FROM vacancies WHERE MATCH('golang') LIMIT 10",
+		"SHOW META LIKE 'total_found'",
+		"UPDATE vacancies SET salary = 1.5 WHERE id IN (1, 2)",
+		"DELETE FROM vacancies WHERE id = 3",
+		"SELECT id FROM resumes",
+	})
+
+	a.NilError(conn.Close())
+	a.NilError(db.Close())
+	a.Equal(len(srv.Errors()), 0)
+}
+
+func TestServerErrors(t *testing.T) {
+	a := assert.New(t)
+	srv, err := NewServer()
+	a.NilError(err)
+	defer srv.Close()
+
+	c, err := dialTestClient(srv.Addr())
+	a.NilError(err)
+	defer c.Close()
+
+	// An empty command packet breaks the connection.
+	c.seq = 0
+	a.NilError(c.writePacket(nil))
+	_, err = c.readPacket()
+	a.NonNilError(err)
+
+	errs := srv.Errors()
+	a.Equal(len(errs), 1)
+	a.Assert(errors.Is(errs[0], errMalformedPacket))
+
+	// Clients disconnecting and closing the server are not errors.
+	c2, err := dialTestClient(srv.Addr())
+	a.NilError(err)
+	_, err = c2.command(comQuit, "")
+	a.NonNilError(err)
+	c2.Close()
+
+	c3, err := dialTestClient(srv.Addr())
+	a.NilError(err)
+	defer c3.Close()
+
+	a.NilError(srv.Close())
+	a.Equal(len(srv.Errors()), 1)
+}
+
+func TestServerClose(t *testing.T) {
+	a := assert.New(t)
+	l, err := net.Listen("unix", filepath.Join(t.TempDir(), "searchd.sock"))
+	a.NilError(err)
+
+	srv := NewServerWithListener(l)
+	conn, err := net.Dial("unix", srv.Addr())
+	a.NilError(err)
+	defer conn.Close()
+
+	c := &testClient{conn: conn, r: bufio.NewReader(conn)}
+	_, err = c.readPacket()
+	a.NilError(err)
+
+	a.NilError(srv.Close())
+	a.NilError(srv.Close())
+
+	_, err = c.query("SELECT 1")
+	a.NonNilError(err)
+
+	_, err = net.Dial("unix", srv.Addr())
+	a.NonNilError(err)
+}
+
+func TestParseShowMeta(t *testing.T) {
+	a := assert.New(t)
+	cases := []struct {
+		query   string
+		ok      bool
+		name    string
+		matched bool
+	}{
+		{"SHOW META", true, "total", true},
+		{" show  meta ; ", true, "total", true},
+		{"SHOW META LIKE 'total%'", true, "total_found", true},
+		{"SHOW META LIKE 'total'", true, "total_found", false},
+		{"SHOW META LIKE 'key_ord[0]'", true, "keyword[0]", true},
+		{`SHOW META LIKE 'a\_b'`, true, "axb", false},
+		{"SHOW META LIKE 'тест%'", true, "тест1", true},
+		{"SHOW METAS", false, "", false},
+		{"SHOW STATUS", false, "", false},
+	}
+
+	for _, c := range cases {
+		a.Use(&c)
+		pattern, ok := parseShowMeta(c.query)
+		a.Equal(ok, c.ok)
+
+		if ok {
+			a.Equal(pattern == nil || pattern.MatchString(c.name), c.matched)
+		}
+	}
+}
@@ -27,6 +27,7 @@ var (
 const (
 	fieldOptWithQuote = "withquote"
 	fieldOptOmitEmpty = "omitempty"
+	fieldOptTime      = "time"
 
 	optName   = "optName"
 	optParams = "optParams"
@@ -43,6 +44,7 @@ type Struct struct {
 
 	structType         reflect.Type
 	structFieldsParser structFieldsParser
+	scanMode           ScanMode
 }
 
 var emptyStruct Struct
@@ -183,7 +185,7 @@ func (s *Struct) UpdateForTag(table string, tag string, value interface{}) *Upda
 			val = dereferencedValue(val)
 		}
 
-		data := val.Interface()
+		data := s.timeFieldValue(sf, f, val.Interface())
 		assignments = append(assignments, ub.Assign(quoted[i], data))
 	}
 
@@ -279,7 +281,7 @@ func (s *Struct) buildColsAndValuesForTag(ib *InsertBuilder, tag string, value .
 			val = dereferencedValue(val)
 
 			if val.IsValid() {
-				values[i] = append(values[i], val.Interface())
+				values[i] = append(values[i], s.timeFieldValue(sf, f, val.Interface()))
 			} else {
 				values[i] = append(values[i], nil)
 			}
@@ -407,7 +409,7 @@ func (s *Struct) AddrWithCols(cols []string, value interface{}) []interface{} {
 
 	for _, c := range cols {
 		name := sf.fieldAlias[c]
-		data := v.FieldByName(name).Addr().Interface()
+		data := s.fieldAddr(sf, c, v.FieldByName(name))
 		addrs = append(addrs, data)
 	}
 
@@ -14,6 +14,7 @@ type structFields struct {
 	taggedFields    map[string][]string
 	quotedFields    map[string]struct{}
 	omitEmptyFields map[string]omitEmptyTagMap
+	timeFields      map[string]TimeEncoding
 }
 
 type structFieldsParser func() *structFields
@@ -33,6 +34,7 @@ func makeFieldsParser(t reflect.Type, mapper FieldMapperFunc, useDefault bool) s
 		taggedFields:    map[string][]string{},
 		quotedFields:    map[string]struct{}{},
 		omitEmptyFields: map[string]omitEmptyTagMap{},
+		timeFields:      map[string]TimeEncoding{},
 	}
 
 	return func() *structFields {
@@ -115,6 +117,11 @@ func (sf *structFields) parse(t reflect.Type, mapper FieldMapperFunc, prefix str
 
 			case fieldOptWithQuote:
 				sf.quotedFields[alias] = struct{}{}
+
+			case fieldOptTime:
+				if enc, ok := parseTimeEncoding(optMap[optParams]); ok {
+					sf.timeFields[alias] = enc
+				}
 			}
 		}
 	}
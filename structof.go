@@ -0,0 +1,160 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+// StructOf is a typed wrapper of `Struct` for struct type T.
+// Values of wrong type, which `Struct` silently ignores, are rejected by the compiler.
+//
+// All methods in StructOf are thread-safe.
+// We can define a global variable to hold a StructOf and use it in any goroutine.
+type StructOf[T any] struct {
+	s *Struct
+}
+
+// NewStructOf analyzes type information of T and creates a new StructOf with all T fields.
+// If T is not a struct, NewStructOf returns a dummy StructOf like `NewStruct`.
+func NewStructOf[T any]() *StructOf[T] {
+	var zero T
+	return &StructOf[T]{
+		s: NewStruct(&zero),
+	}
+}
+
+// Struct returns the underlying untyped `Struct`.
+func (s *StructOf[T]) Struct() *Struct {
+	return s.s
+}
+
+// For sets the default flavor of s and returns a shadow copy of s.
+// The original s is not changed.
+func (s *StructOf[T]) For(flavor Flavor) *StructOf[T] {
+	return &StructOf[T]{s: s.s.For(flavor)}
+}
+
+// WithFieldMapper returns a new StructOf based on s with custom field mapper.
+// The original s is not changed.
+func (s *StructOf[T]) WithFieldMapper(mapper FieldMapperFunc) *StructOf[T] {
+	return &StructOf[T]{s: s.s.WithFieldMapper(mapper)}
+}
+
+// SelectFrom creates a new `SelectBuilder` with table name.
+// See `Struct#SelectFrom` for details.
+func (s *StructOf[T]) SelectFrom(table string) *SelectBuilder {
+	return s.s.SelectFrom(table)
+}
+
+// SelectFromForTag creates a new `SelectBuilder` with table name for a specified tag.
+// See `Struct#SelectFromForTag` for details.
+func (s *StructOf[T]) SelectFromForTag(table string, tag string) *SelectBuilder {
+	return s.s.SelectFromForTag(table, tag)
+}
+
+// Update creates a new `UpdateBuilder` with table name.
+// See `Struct#Update` for details.
+func (s *StructOf[T]) Update(table string, value T) *UpdateBuilder {
+	return s.s.Update(table, value)
+}
+
+// UpdateForTag creates a new `UpdateBuilder` with table name for a specified tag.
+// See `Struct#UpdateForTag` for details.
+func (s *StructOf[T]) UpdateForTag(table string, tag string, value T) *UpdateBuilder {
+	return s.s.UpdateForTag(table, tag, value)
+}
+
+// InsertInto creates a new `InsertBuilder` with table name using verb INSERT INTO.
+// See `Struct#InsertInto` for details.
+func (s *StructOf[T]) InsertInto(table string, value ...T) *InsertBuilder {
+	return s.s.InsertInto(table, toInterfaces(value)...)
+}
+
+// InsertIgnoreInto creates a new `InsertBuilder` with table name using verb INSERT IGNORE INTO.
+// See `Struct#InsertIgnoreInto` for details.
+func (s *StructOf[T]) InsertIgnoreInto(table string, value ...T) *InsertBuilder {
+	return s.s.InsertIgnoreInto(table, toInterfaces(value)...)
+}
+
+// ReplaceInto creates a new `InsertBuilder` with table name using verb REPLACE INTO.
+// See `Struct#ReplaceInto` for details.
+func (s *StructOf[T]) ReplaceInto(table string, value ...T) *InsertBuilder {
+	return s.s.ReplaceInto(table, toInterfaces(value)...)
+}
+
+// InsertIntoForTag creates a new `InsertBuilder` with table name using verb INSERT INTO for a specified tag.
+// See `Struct#InsertIntoForTag` for details.
+func (s *StructOf[T]) InsertIntoForTag(table string, tag string, value ...T) *InsertBuilder {
+	return s.s.InsertIntoForTag(table, tag, toInterfaces(value)...)
+}
+
+// InsertIgnoreIntoForTag creates a new `InsertBuilder` with table name using verb INSERT IGNORE INTO for a specified tag.
+// See `Struct#InsertIgnoreIntoForTag` for details.
+func (s *StructOf[T]) InsertIgnoreIntoForTag(table string, tag string, value ...T) *InsertBuilder {
+	return s.s.InsertIgnoreIntoForTag(table, tag, toInterfaces(value)...)
+}
+
+// ReplaceIntoForTag creates a new `InsertBuilder` with table name using verb REPLACE INTO for a specified tag.
+// See `Struct#ReplaceIntoForTag` for details.
+func (s *StructOf[T]) ReplaceIntoForTag(table string, tag string, value ...T) *InsertBuilder {
+	return s.s.ReplaceIntoForTag(table, tag, toInterfaces(value)...)
+}
+
+// DeleteFrom creates a new `DeleteBuilder` with table name.
+func (s *StructOf[T]) DeleteFrom(table string) *DeleteBuilder {
+	return s.s.DeleteFrom(table)
+}
+
+// Addr takes address of all exported fields of value.
+// The returned result can be used in `Row#Scan` directly.
+func (s *StructOf[T]) Addr(value *T) []interface{} {
+	return s.s.Addr(value)
+}
+
+// AddrForTag takes address of all fields of value tagged with tag.
+// The returned result can be used in `Row#Scan` directly.
+func (s *StructOf[T]) AddrForTag(tag string, value *T) []interface{} {
+	return s.s.AddrForTag(tag, value)
+}
+
+// AddrWithCols takes address of all columns defined in cols from value.
+// The returned result can be used in `Row#Scan` directly.
+func (s *StructOf[T]) AddrWithCols(cols []string, value *T) []interface{} {
+	return s.s.AddrWithCols(cols, value)
+}
+
+// WithScanMode returns a new StructOf based on s with the scan mode.
+// The original s is not changed.
+func (s *StructOf[T]) WithScanMode(mode ScanMode) *StructOf[T] {
+	return &StructOf[T]{s: s.s.WithScanMode(mode)}
+}
+
+// ScanRow scans the current row of rows into value.
+// See `Struct#ScanRow` for details.
+func (s *StructOf[T]) ScanRow(rows Rows, value *T) error {
+	return s.s.ScanRow(rows, value)
+}
+
+// ScanAll reads all rows into a slice of T.
+// Columns of rows are mapped to fields by names, so the order of columns doesn't matter.
+// Columns without fields are handled according to the scan mode set by `StructOf#WithScanMode`.
+//
+// Caller is responsible to close rows.
+func (s *StructOf[T]) ScanAll(rows Rows) ([]T, error) {
+	var values []T
+
+	if err := s.s.ScanRows(rows, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func toInterfaces[T any](values []T) []interface{} {
+	ifaces := make([]interface{}, 0, len(values))
+
+	for _, v := range values {
+		ifaces = append(ifaces, v)
+	}
+
+	return ifaces
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+var userOfTest = NewStructOf[structUserForTest]()
+
+func ExampleStructOf() {
+	type Vacancy struct {
+		ID     int    `db:"id"`
+		Title  string `db:"title"`
+		Salary int    `db:"salary" fieldopt:"omitempty"`
+	}
+
+	vacancies := NewStructOf[Vacancy]()
+
+	ib := vacancies.InsertInto("vacancies", Vacancy{1, "Go developer", 200000}, Vacancy{2, "QA", 100000})
+	s, args := ib.Build()
+	fmt.Println(s)
+	fmt.Println(args)
+
+	ub := vacancies.Update("vacancies", Vacancy{ID: 1, Title: "Senior Go developer"})
+	ub.Where(ub.Equal("id", 1))
+	s, args = ub.Build()
+	fmt.Println(s)
+	fmt.Println(args)
+
+	// Output:
+	// INSERT INTO vacancies (id, title, salary) VALUES (?, ?, ?), (?, ?, ?)
+	// [1 Go developer 200000 2 QA 100000]
+	// UPDATE vacancies SET id = ?, title = ? WHERE id = ?
+	// [1 Senior Go developer 1]
+}
+
+func TestStructOfBuilders(t *testing.T) {
+	a := assert.New(t)
+	user := structUserForTest{ID: 123, Name: "Huan Du", Status: 2, CreatedAt: 1234567890}
+
+	s, _ := userOfTest.SelectFromForTag("user", "important").Build()
+	a.Equal(s, "SELECT user.id, user.Name, user.status FROM user")
+
+	s, args := userOfTest.UpdateForTag("user", "important", user).Build()
+	a.Equal(s, "UPDATE user SET id = ?, Name = ?, status = ?")
+	a.Equal(args, []interface{}{123, "Huan Du", 2})
+
+	s, args = userOfTest.ReplaceIntoForTag("user", "important", user).Build()
+	a.Equal(s, "REPLACE INTO user (id, Name, status) VALUES (?, ?, ?)")
+	a.Equal(args, []interface{}{123, "Huan Du", 2})
+
+	s, _ = userOfTest.DeleteFrom("user").Build()
+	a.Equal(s, "DELETE FROM user")
+
+	a.Equal(userOfTest.Addr(&user), []interface{}{&user.ID, &user.Name, &user.Status, &user.CreatedAt})
+	a.Equal(userOfTest.AddrWithCols([]string{"status", "id"}, &user), []interface{}{&user.Status, &user.ID})
+	a.Equal(userOfTest.For(SphinxSearch).Struct().Flavor, SphinxSearch)
+}
+
+func TestStructOfScanAll(t *testing.T) {
+	a := assert.New(t)
+	rows := &memRows{
+		cols: []string{"status", "id", "Name"},
+		rows: [][]interface{}{
+			{1, 10, "foo"},
+			{2, 20, "bar"},
+		},
+	}
+
+	users, err := userOfTest.ScanAll(rows)
+	a.NilError(err)
+	a.Equal(users, []structUserForTest{
+		{ID: 10, Name: "foo", Status: 1},
+		{ID: 20, Name: "bar", Status: 2},
+	})
+
+	users, err = userOfTest.ScanAll(&memRows{cols: []string{"id"}})
+	a.NilError(err)
+	a.Equal(len(users), 0)
+
+	_, err = userOfTest.ScanAll(&memRows{cols: []string{"id", "unknown"}})
+	a.Assert(errors.Is(err, ErrScanUnknownColumn))
+
+	errRows := errors.New("connection lost")
+	_, err = userOfTest.ScanAll(&memRows{cols: []string{"id"}, err: errRows})
+	a.Equal(err, errRows)
+
+	_, err = NewStructOf[int]().ScanAll(&memRows{cols: []string{"id"}})
+	a.Assert(errors.Is(err, ErrScanInvalidDest))
+
+	users, err = userOfTest.WithScanMode(ScanLenient).ScanAll(&memRows{
+		cols: []string{"id", "w"},
+		rows: [][]interface{}{{10, 1500}},
+	})
+	a.NilError(err)
+	a.Equal(users, []structUserForTest{{ID: 10}})
+}
@@ -0,0 +1,263 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrScanTime means that a column value cannot be converted to time.Time.
+var ErrScanTime = errors.New("go-sphinxql: cannot convert value to time.Time")
+
+// TimeEncoding is the representation of time.Time in SphinxQL.
+type TimeEncoding int
+
+// TimeEncoding enum
+const (
+	// TimeUnix encodes time as unix seconds, which is the value of `timestamp` attributes.
+	// A zero time is encoded as 0.
+	TimeUnix TimeEncoding = iota
+
+	// TimeUnixMilli encodes time as unix milliseconds, which fits `bigint` attributes.
+	// A zero time is encoded as 0.
+	TimeUnixMilli
+
+	// TimeString encodes time in UTC as a string like '2006-01-02 15:04:05.999999', which fits `string` attributes.
+	// A zero time is encoded as '0000-00-00'. Strings are scanned as UTC too.
+	TimeString
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	timePtrType = reflect.PtrTo(timeType)
+)
+
+// SetTimeEncoding sets the encoding of time.Time for interpolation with flavor f.
+// The default is TimeUnix.
+//
+// SetTimeEncoding is expected to be called once during initialization.
+func (f Flavor) SetTimeEncoding(enc TimeEncoding) {
+	f.updateSettings(func(fs *flavorSettings) {
+		fs.timeEncoding = enc
+	})
+}
+
+// TimeEncoding returns the encoding of time.Time for interpolation with flavor f.
+func (f Flavor) TimeEncoding() TimeEncoding {
+	return f.settings().timeEncoding
+}
+
+type timeArgs struct {
+	t   time.Time
+	enc TimeEncoding
+}
+
+// TimeAs marks t to be encoded with enc regardless of the time encoding of a flavor.
+// It's handy when attributes of different types keep time in the same index.
+func TimeAs(t time.Time, enc TimeEncoding) interface{} {
+	return timeArgs{t: t, enc: enc}
+}
+
+// Value implements `driver.Valuer`, so that the time is passed to `database/sql` in the same representation.
+func (ta timeArgs) Value() (driver.Value, error) {
+	switch ta.enc {
+	case TimeUnixMilli:
+		return unixMilli(ta.t), nil
+	case TimeString:
+		return string(appendTimeString(nil, ta.t)), nil
+	}
+
+	return unixSeconds(ta.t), nil
+}
+
+func appendTime(buf []byte, t time.Time, enc TimeEncoding) []byte {
+	switch enc {
+	case TimeUnixMilli:
+		return strconv.AppendInt(buf, unixMilli(t), 10)
+	case TimeString:
+		buf = append(buf, '\'')
+		buf = appendTimeString(buf, t)
+		return append(buf, '\'')
+	}
+
+	return strconv.AppendInt(buf, unixSeconds(t), 10)
+}
+
+func appendTimeString(buf []byte, t time.Time) []byte {
+	if t.IsZero() {
+		return append(buf, "0000-00-00"...)
+	}
+
+	// In SQL standard, the precision of fractional seconds in time literal is up to 6 digits.
+	// Round up t. The time is written in UTC, which is the location of parsed strings.
+	t = t.UTC().Add(500 * time.Nanosecond)
+	return append(buf, t.Format("2006-01-02 15:04:05.999999")...)
+}
+
+func unixSeconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
+
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixMilli()
+}
+
+// timeScanner scans a column value into a time.Time.
+// Integers are treated as unix time according to enc, and strings can be either integers or formatted time.
+//
+// If ptr is set, the time is scanned into a new time.Time pointed by ptr, and NULL sets *ptr to nil.
+type timeScanner struct {
+	dest *time.Time
+	ptr  **time.Time
+	enc  TimeEncoding
+}
+
+// Scan implements `sql.Scanner`.
+func (ts timeScanner) Scan(src interface{}) error {
+	if ts.ptr != nil {
+		if src == nil {
+			*ts.ptr = nil
+			return nil
+		}
+
+		t := new(time.Time)
+
+		if err := (timeScanner{dest: t, enc: ts.enc}).Scan(src); err != nil {
+			return err
+		}
+
+		*ts.ptr = t
+		return nil
+	}
+
+	switch v := src.(type) {
+	case nil:
+		*ts.dest = time.Time{}
+	case time.Time:
+		*ts.dest = v
+	case int64:
+		*ts.dest = ts.fromInt(v)
+	case uint64:
+		*ts.dest = ts.fromInt(int64(v))
+	case []byte:
+		return ts.parse(string(v))
+	case string:
+		return ts.parse(v)
+	default:
+		return fmt.Errorf("%w: %T", ErrScanTime, src)
+	}
+
+	return nil
+}
+
+func (ts timeScanner) fromInt(v int64) time.Time {
+	switch {
+	case v == 0:
+		return time.Time{}
+	case ts.enc == TimeUnixMilli:
+		return time.UnixMilli(v)
+	}
+
+	return time.Unix(v, 0)
+}
+
+func (ts timeScanner) parse(s string) error {
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		*ts.dest = ts.fromInt(v)
+		return nil
+	}
+
+	if strings.HasPrefix(s, "0000-00-00") {
+		*ts.dest = time.Time{}
+		return nil
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			*ts.dest = t
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrScanTime, s)
+}
+
+// timeFieldValue returns the value of a field for INSERT or UPDATE.
+// A time.Time is marked to be encoded according to the field option or the time encoding of s.Flavor,
+// so that it's passed to a driver in the same representation as it's interpolated.
+// A nil *time.Time is NULL.
+func (s *Struct) timeFieldValue(sf *structFields, alias string, data interface{}) interface{} {
+	var t time.Time
+
+	switch v := data.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+
+		t = *v
+	default:
+		return data
+	}
+
+	return TimeAs(t, s.timeEncoding(sf, alias))
+}
+
+// fieldAddr returns the address of a field for scanning.
+// A time.Time or *time.Time field is wrapped by a scanner converting unix time and strings.
+func (s *Struct) fieldAddr(sf *structFields, alias string, field reflect.Value) interface{} {
+	switch field.Type() {
+	case timeType:
+		return timeScanner{
+			dest: field.Addr().Interface().(*time.Time),
+			enc:  s.timeEncoding(sf, alias),
+		}
+	case timePtrType:
+		return timeScanner{
+			ptr: field.Addr().Interface().(**time.Time),
+			enc: s.timeEncoding(sf, alias),
+		}
+	}
+
+	return field.Addr().Interface()
+}
+
+// timeEncoding returns the encoding set by the field option or the time encoding of s.Flavor.
+func (s *Struct) timeEncoding(sf *structFields, alias string) TimeEncoding {
+	if enc, ok := sf.timeFields[alias]; ok {
+		return enc
+	}
+
+	return s.Flavor.TimeEncoding()
+}
+
+func parseTimeEncoding(name string) (TimeEncoding, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "unix":
+		return TimeUnix, true
+	case "unixmilli":
+		return TimeUnixMilli, true
+	case "string":
+		return TimeString, true
+	}
+
+	return TimeUnix, false
+}
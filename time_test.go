@@ -0,0 +1,219 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/huandu/go-assert"
+)
+
+func setTestTimeEncoding(t *testing.T, enc TimeEncoding) {
+	old := SphinxSearch.TimeEncoding()
+	SphinxSearch.SetTimeEncoding(enc)
+	t.Cleanup(func() {
+		SphinxSearch.SetTimeEncoding(old)
+	})
+}
+
+func ExampleTimeAs() {
+	type Event struct {
+		ID        int       `db:"id"`
+		CreatedAt time.Time `db:"created_at"`
+		Date      time.Time `db:"date" fieldopt:"time(string)"`
+	}
+
+	created := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	events := NewStruct(Event{})
+	ib := events.InsertInto("events", Event{ID: 1, CreatedAt: created, Date: created})
+	s, args := ib.Build()
+	query, _ := SphinxSearch.Interpolate(s, args)
+	fmt.Println(query)
+
+	sb := NewSelectBuilder()
+	sb.Select("id").From("events")
+	sb.Where(sb.GreaterEqualThan("created_at", created), sb.Equal("updated_at_ms", TimeAs(created, TimeUnixMilli)))
+	s, args = sb.Build()
+	query, _ = SphinxSearch.Interpolate(s, args)
+	fmt.Println(query)
+
+	// Output:
+	// INSERT INTO events (id, created_at, date) VALUES (1, 1646370367, '2022-03-04 05:06:07')
+	// SELECT id FROM events WHERE created_at >= 1646370367 AND updated_at_ms = 1646370367000
+}
+
+func TestInterpolateTime(t *testing.T) {
+	a := assert.New(t)
+	tm := time.Date(2022, 3, 4, 5, 6, 7, 8000000, time.UTC)
+	cases := []struct {
+		enc      TimeEncoding
+		expected string
+	}{
+		{TimeUnix, "SELECT 1646370367, 0, 1646370367008"},
+		{TimeUnixMilli, "SELECT 1646370367008, 0, 1646370367008"},
+		{TimeString, "SELECT '2022-03-04 05:06:07.008', '0000-00-00', 1646370367008"},
+	}
+
+	for _, c := range cases {
+		a.Use(&c)
+		setTestTimeEncoding(t, c.enc)
+
+		query, err := SphinxSearch.Interpolate("SELECT ?, ?, ?", []interface{}{tm, time.Time{}, TimeAs(tm, TimeUnixMilli)})
+		a.NilError(err)
+		a.Equal(query, c.expected)
+	}
+}
+
+func TestTimeAsValue(t *testing.T) {
+	a := assert.New(t)
+	tm := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	cases := []struct {
+		arg      interface{}
+		expected interface{}
+	}{
+		{TimeAs(tm, TimeUnix), int64(1646370367)},
+		{TimeAs(tm, TimeUnixMilli), int64(1646370367000)},
+		{TimeAs(tm, TimeString), "2022-03-04 05:06:07"},
+		{TimeAs(time.Time{}, TimeUnix), int64(0)},
+		{TimeAs(time.Time{}, TimeString), "0000-00-00"},
+	}
+
+	for _, c := range cases {
+		a.Use(&c)
+		v, err := c.arg.(timeArgs).Value()
+		a.NilError(err)
+		a.Equal(v, c.expected)
+	}
+}
+
+func TestStructScanTime(t *testing.T) {
+	type event struct {
+		ID        int       `db:"id"`
+		CreatedAt time.Time `db:"created_at"`
+		UpdatedAt time.Time `db:"updated_at" fieldopt:"time(unixmilli)"`
+		Date      time.Time `db:"date" fieldopt:"time(string)"`
+	}
+
+	a := assert.New(t)
+	events := NewStruct(event{})
+	rows := &memRows{
+		cols: []string{"id", "created_at", "updated_at", "date"},
+		rows: [][]interface{}{
+			{1, int64(1646370367), []byte("1646370367008"), "2022-03-04 05:06:07"},
+			{2, int64(0), nil, "0000-00-00"},
+			{3, "2022-03-04", int64(1646370367000), "1646370367"},
+		},
+	}
+
+	var result []event
+	a.NilError(events.ScanRows(rows, &result))
+	a.Equal(len(result), 3)
+
+	a.Equal(result[0].CreatedAt.Unix(), int64(1646370367))
+	a.Equal(result[0].UpdatedAt.UnixMilli(), int64(1646370367008))
+	a.Assert(result[0].Date.Equal(time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)))
+	a.Assert(result[1].CreatedAt.IsZero() && result[1].UpdatedAt.IsZero() && result[1].Date.IsZero())
+	a.Assert(result[2].CreatedAt.Equal(time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC)))
+	a.Equal(result[2].UpdatedAt.Unix(), int64(1646370367))
+	a.Equal(result[2].Date.Unix(), int64(1646370367))
+
+	var e event
+	addrs := events.AddrWithCols([]string{"id", "updated_at"}, &e)
+	a.Equal(addrs[0], &e.ID)
+	a.NilError(addrs[1].(timeScanner).Scan(int64(1000)))
+	a.Equal(e.UpdatedAt.Unix(), int64(1))
+
+	err := addrs[1].(timeScanner).Scan("yesterday")
+	a.Assert(errors.Is(err, ErrScanTime))
+}
+
+func TestStructTimeValue(t *testing.T) {
+	type event struct {
+		ID        int        `db:"id"`
+		CreatedAt time.Time  `db:"created_at"`
+		UpdatedAt *time.Time `db:"updated_at" fieldopt:"time(unixmilli)"`
+		DeletedAt *time.Time `db:"deleted_at"`
+	}
+
+	a := assert.New(t)
+	setTestTimeEncoding(t, TimeString)
+	tm := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	events := NewStruct(event{})
+	e := event{ID: 1, CreatedAt: tm, UpdatedAt: &tm}
+
+	// Values are passed to a driver in the same representation as they are interpolated.
+	_, args := events.InsertInto("events", &e).Build()
+	values := make([]interface{}, 0, len(args))
+
+	for _, arg := range args {
+		v, err := driver.DefaultParameterConverter.ConvertValue(arg)
+		a.NilError(err)
+		values = append(values, v)
+	}
+
+	a.Equal(values, []interface{}{int64(1), "2022-03-04 05:06:07", int64(1646370367000), nil})
+
+	s, args := events.Update("events", &e).Build()
+	query, err := SphinxSearch.Interpolate(s, args)
+	a.NilError(err)
+	a.Equal(query, "UPDATE events SET id = 1, created_at = '2022-03-04 05:06:07', updated_at = 1646370367000, deleted_at = NULL")
+}
+
+func TestStructScanTimePtr(t *testing.T) {
+	type event struct {
+		ID        int        `db:"id"`
+		UpdatedAt *time.Time `db:"updated_at" fieldopt:"time(unixmilli)"`
+		DeletedAt *time.Time `db:"deleted_at"`
+	}
+
+	a := assert.New(t)
+	events := NewStruct(event{})
+	rows := &memRows{
+		cols: []string{"id", "updated_at", "deleted_at"},
+		rows: [][]interface{}{
+			{1, int64(1646370367008), int64(1646370367)},
+			{2, nil, "0000-00-00"},
+		},
+	}
+
+	var result []event
+	a.NilError(events.ScanRows(rows, &result))
+	a.Equal(len(result), 2)
+	a.Equal(result[0].UpdatedAt.UnixMilli(), int64(1646370367008))
+	a.Equal(result[0].DeletedAt.Unix(), int64(1646370367))
+	a.Equal(result[1].UpdatedAt, (*time.Time)(nil))
+	a.Assert(result[1].DeletedAt != nil && result[1].DeletedAt.IsZero())
+
+	e := event{UpdatedAt: &time.Time{}}
+	addrs := events.AddrWithCols([]string{"updated_at"}, &e)
+	a.NilError(addrs[0].(timeScanner).Scan(nil))
+	a.Equal(e.UpdatedAt, (*time.Time)(nil))
+
+	err := addrs[0].(timeScanner).Scan("yesterday")
+	a.Assert(errors.Is(err, ErrScanTime))
+	a.Equal(e.UpdatedAt, (*time.Time)(nil))
+}
+
+func TestTimeStringRoundTrip(t *testing.T) {
+	a := assert.New(t)
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	tm := time.Date(2022, 3, 4, 5, 6, 7, 8000, loc)
+
+	v, err := TimeAs(tm, TimeString).(timeArgs).Value()
+	a.NilError(err)
+	a.Equal(v, "2022-03-04 02:06:07.000008")
+
+	query, err := SphinxSearch.Interpolate("SELECT ?", []interface{}{TimeAs(tm, TimeString)})
+	a.NilError(err)
+	a.Equal(query, "SELECT '2022-03-04 02:06:07.000008'")
+
+	var scanned time.Time
+	a.NilError(timeScanner{dest: &scanned, enc: TimeString}.Scan(v))
+	a.Assert(scanned.Equal(tm))
+}
@@ -137,10 +137,25 @@ func (ub *UpdateBuilder) Div(field string, value interface{}) string {
 
 // String returns the compiled UPDATE string.
 func (ub *UpdateBuilder) String() string {
-	s, _ := ub.Build()
+	s, _ := ub.build(ub.args.Flavor)
 	return s
 }
 
+// StringInterpolated returns the compiled UPDATE string with args interpolated.
+// Like String, it doesn't call hooks. It's designed for logging and debugging;
+// use BuildInterpolated to send a query, as the error of interpolation is returned in place of the query.
+func (ub *UpdateBuilder) StringInterpolated() string {
+	sql, args := ub.build(ub.args.Flavor)
+	return interpolatedString(ub.args.Flavor, sql, args)
+}
+
+// BuildInterpolated returns compiled UPDATE string with args interpolated by the flavor of ub.
+// It's designed for searchd, which doesn't support server-side prepared statements.
+func (ub *UpdateBuilder) BuildInterpolated() (string, error) {
+	sql, args := ub.Build()
+	return ub.args.Flavor.Interpolate(sql, args)
+}
+
 // Build returns compiled UPDATE string and args.
 // They can be used in `DB#Query` of package `database/sql` directly.
 func (ub *UpdateBuilder) Build() (sql string, args []interface{}) {
@@ -150,6 +165,12 @@ func (ub *UpdateBuilder) Build() (sql string, args []interface{}) {
 // BuildWithFlavor returns compiled UPDATE string and args with flavor and initial args.
 // They can be used in `DB#Query` of package `database/sql` directly.
 func (ub *UpdateBuilder) BuildWithFlavor(flavor Flavor, initialArg ...interface{}) (sql string, args []interface{}) {
+	sql, args = ub.build(flavor, initialArg...)
+	return runHooks(ub, flavor, sql, args)
+}
+
+// build builds ub without calling hooks.
+func (ub *UpdateBuilder) build(flavor Flavor, initialArg ...interface{}) (sql string, args []interface{}) {
 	buf := &strings.Builder{}
 	ub.injection.WriteTo(buf, updateMarkerInit)
 	buf.WriteString("UPDATE ")
@@ -175,6 +196,22 @@ func (ub *UpdateBuilder) BuildWithFlavor(flavor Flavor, initialArg ...interface{
 	return ub.args.CompileWithFlavor(buf.String(), flavor, initialArg...)
 }
 
+// Validate checks ub for mistakes which are not reported until the query reaches the server.
+// It returns the first found error or nil.
+func (ub *UpdateBuilder) Validate() error {
+	return ub.ValidateWithFlavor(ub.args.Flavor)
+}
+
+// ValidateWithFlavor checks ub like Validate with settings of flavor,
+// which must be the flavor passed to `BuildWithFlavor`.
+func (ub *UpdateBuilder) ValidateWithFlavor(flavor Flavor) error {
+	return validateEmptyList(ub.args, flavor)
+}
+
+func (ub *UpdateBuilder) defaultFlavor() Flavor {
+	return ub.args.Flavor
+}
+
 // SetFlavor sets the flavor of compiled sql.
 func (ub *UpdateBuilder) SetFlavor(flavor Flavor) (old Flavor) {
 	old = ub.args.Flavor
@@ -182,6 +219,32 @@ func (ub *UpdateBuilder) SetFlavor(flavor Flavor) (old Flavor) {
 	return
 }
 
+// Clone returns a deep copy of ub.
+// Args, injected SQLs and nested builders are copied, so ub and the copy
+// can be modified and built independently, e.g. in different goroutines.
+func (ub *UpdateBuilder) Clone() *UpdateBuilder {
+	args := ub.args.clone()
+	return &UpdateBuilder{
+		Cond: Cond{
+			Args: args,
+		},
+		Opt: Opt{
+			Args: args,
+		},
+		table:       ub.table,
+		assignments: cloneStrings(ub.assignments),
+		whereExprs:  cloneStrings(ub.whereExprs),
+		optionExprs: cloneStrings(ub.optionExprs),
+		args:        args,
+		injection:   ub.injection.clone(),
+		marker:      ub.marker,
+	}
+}
+
+func (ub *UpdateBuilder) cloneBuilder() Builder {
+	return ub.Clone()
+}
+
 // SQL adds an arbitrary sql to current position.
 func (ub *UpdateBuilder) SQL(sql string) *UpdateBuilder {
 	ub.injection.SQL(ub.marker, sql)
@@ -115,3 +115,20 @@ func ExampleUpdateBuilder_SQL() {
 	// Output:
 	// /* before */ UPDATE demo.user /* after update */ SET type = ? /* after set */
 }
+
+func TestUpdateBuilderClone(t *testing.T) {
+	a := assert.New(t)
+	base := NewUpdateBuilder()
+	base.Update("vacancies").Set(base.Assign("status", 1)).Where(base.E("id", 1))
+
+	c := base.Clone()
+	c.SetMore(c.Assign("salary", 100)).Where(c.E("company_id", 2)).Option(c.Comment("clone"))
+
+	s, args := base.Build()
+	a.Equal(s, "UPDATE vacancies SET status = ? WHERE id = ?")
+	a.Equal(args, []interface{}{1, 1})
+
+	s, args = c.Build()
+	a.Equal(s, "UPDATE vacancies SET status = ?, salary = ? WHERE id = ? AND company_id = ? OPTION comment = ?")
+	a.Equal(args, []interface{}{1, 100, 1, 2, "clone"})
+}
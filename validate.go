@@ -0,0 +1,155 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrMultipleMatch means that there is more than one MATCH in a query.
+	// The searchd allows only one MATCH per query.
+	ErrMultipleMatch = errors.New("go-sphinxql: only one MATCH is allowed in a query")
+
+	// ErrMatchInsideOr means that MATCH is used as an operand of OR, which the searchd refuses.
+	ErrMatchInsideOr = errors.New("go-sphinxql: MATCH cannot be used inside OR")
+
+	// ErrMatchInsideNot means that MATCH is negated by NOT, which the searchd refuses.
+	ErrMatchInsideNot = errors.New("go-sphinxql: MATCH cannot be used inside NOT")
+)
+
+// matchUsage describes how MATCH is used in an expression.
+type matchUsage struct {
+	count     int
+	insideOr  bool
+	insideNot bool
+}
+
+// err returns the error of the first found problem in mu.
+func (mu matchUsage) err() error {
+	switch {
+	case mu.count > 1:
+		return ErrMultipleMatch
+	case mu.insideOr:
+		return ErrMatchInsideOr
+	case mu.insideNot:
+		return ErrMatchInsideNot
+	}
+
+	return nil
+}
+
+type matchGroup struct {
+	hasOr    bool
+	hasMatch bool
+	negated  bool
+}
+
+// analyzeMatch scans expr and finds out how MATCH is used in it.
+// Quoted strings are skipped, so a MATCH inside a literal doesn't count.
+func analyzeMatch(expr string) (mu matchUsage) {
+	stack := []*matchGroup{{}}
+	var prev, word string
+
+	closeGroup := func() {
+		g := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if !g.hasMatch {
+			return
+		}
+
+		if g.hasOr {
+			mu.insideOr = true
+		}
+
+		if g.negated {
+			mu.insideNot = true
+		}
+
+		if len(stack) > 0 {
+			stack[len(stack)-1].hasMatch = true
+		}
+	}
+
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(expr, i)
+			prev, word = "", ""
+
+		case isIdentByte(c):
+			start := i
+
+			for i+1 < len(expr) && isIdentByte(expr[i+1]) {
+				i++
+			}
+
+			word = strings.ToUpper(expr[start : i+1])
+
+			switch word {
+			case "OR":
+				stack[len(stack)-1].hasOr = true
+			case "MATCH":
+				mu.count++
+				stack[len(stack)-1].hasMatch = true
+
+				if prev == "NOT" {
+					mu.insideNot = true
+				}
+			}
+
+			prev = word
+
+		case c == '(':
+			stack = append(stack, &matchGroup{
+				negated: prev == "NOT",
+			})
+			prev = ""
+
+		case c == ')':
+			if len(stack) > 1 {
+				closeGroup()
+			}
+
+			prev = ""
+
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			// Keep prev.
+
+		default:
+			prev = ""
+		}
+	}
+
+	for len(stack) > 0 {
+		closeGroup()
+	}
+
+	return
+}
+
+// skipQuoted returns the index of the closing quote of a quoted string starting at start.
+func skipQuoted(s string, start int) int {
+	quote := s[start]
+
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+
+	return len(s)
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '@' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 Huan Du. All rights reserved.
+// Copyright 2022 OOO SuperJob. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package sphinxql
+
+import (
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestAnalyzeMatch(t *testing.T) {
+	a := assert.New(t)
+	cases := map[string]matchUsage{
+		"a = 1":                                 {},
+		"MATCH($0) AND a = 1":                   {count: 1},
+		"match('x') AND (a = 1 OR b = 2)":       {count: 1},
+		"(MATCH($0) AND a = 1) AND (b OR c)":    {count: 1},
+		"MATCH($0) AND MATCH($1)":               {count: 2},
+		"(a = 1 OR MATCH($0))":                  {count: 1, insideOr: true},
+		"((MATCH($0) AND a = 1) OR b = 2)":      {count: 1, insideOr: true},
+		"MATCH($0) AND a = 1 OR b = 2":          {count: 1, insideOr: true},
+		"NOT MATCH($0)":                         {count: 1, insideNot: true},
+		"NOT ((MATCH($0) AND a = 1))":           {count: 1, insideNot: true},
+		"a NOT IN (1, 2) AND MATCH($0)":         {count: 1},
+		"title = 'MATCH(x) OR y' AND MATCH($0)": {count: 1},
+		"title = 'it\\'s OR' AND MATCH($0)":     {count: 1},
+	}
+
+	for expr, expected := range cases {
+		a.Use(&expr)
+		a.Equal(analyzeMatch(expr), expected)
+	}
+}
+
+func TestSelectBuilderValidateMatch(t *testing.T) {
+	a := assert.New(t)
+	cases := []struct {
+		where func(sb *SelectBuilder) []string
+		err   error
+	}{
+		{
+			func(sb *SelectBuilder) []string {
+				return []string{sb.Match("golang"), sb.Not(In("city_id", 1, 2))}
+			},
+			nil,
+		},
+		{
+			func(sb *SelectBuilder) []string {
+				return []string{sb.Match("golang"), sb.Match("rust")}
+			},
+			ErrMultipleMatch,
+		},
+		{
+			func(sb *SelectBuilder) []string {
+				return []string{sb.Or(sb.Match("golang"), sb.E("remote", 1))}
+			},
+			ErrMatchInsideOr,
+		},
+		{
+			func(sb *SelectBuilder) []string {
+				return []string{sb.Expr(Or(Match("golang"), In("city_id", 1)))}
+			},
+			ErrMatchInsideOr,
+		},
+		{
+			func(sb *SelectBuilder) []string {
+				return []string{sb.Not(Match("golang"))}
+			},
+			ErrMatchInsideNot,
+		},
+	}
+
+	for i, c := range cases {
+		a.Use(&i)
+		sb := NewSelectBuilder().Select("id").From("vacancies")
+		sb.Where(c.where(sb)...)
+		a.Equal(sb.Validate(), c.err)
+	}
+}